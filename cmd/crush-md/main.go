@@ -6,6 +6,7 @@ import (
 
 	"crush-session-explorer/internal/cli"
 
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/spf13/cobra"
 )
 
@@ -22,8 +23,24 @@ func main() {
 	// Add export command
 	rootCmd.AddCommand(cli.ExportCmd())
 
+	// Add AICS interchange commands
+	rootCmd.AddCommand(cli.ExportAICSCmd())
+	rootCmd.AddCommand(cli.ImportAICSCmd())
+
+	// Add agent-bound session commands
+	rootCmd.AddCommand(cli.AgentCmd())
+
+	// Add branch commands for forking and listing session history
+	rootCmd.AddCommand(cli.BranchCmd())
+
+	// Add the embedded HTTP session browser
+	rootCmd.AddCommand(cli.ServeCmd())
+
+	// Add shell completion generation
+	rootCmd.AddCommand(cli.CompletionCmd())
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"fmt"
+
+	"crush-session-explorer/internal/providers"
+
+	"github.com/spf13/cobra"
+)
+
+// AgentCmd creates the agent command, which groups subcommands for
+// starting and continuing sessions bound to a configured agent persona.
+func AgentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Start or continue a session bound to a configured agent persona",
+		Long: `Agents group a system prompt, a curated toolbox, and a default model
+into a reusable persona (e.g. "coding", "review") that can be attached when
+starting a new session or replying to an existing one. Agents are loaded
+from ~/.config/crush-session-explorer/agents.yaml (or --agents-config).`,
+	}
+
+	cmd.AddCommand(newAgentSessionCmd())
+	cmd.AddCommand(replyAgentSessionCmd())
+
+	return cmd
+}
+
+// resolveAgent loads the agents config (configPath, or the default location
+// if empty) and looks up name within it.
+func resolveAgent(configPath, name string) (providers.Agent, error) {
+	var agents map[string]providers.Agent
+	var err error
+	if configPath != "" {
+		agents, err = providers.LoadAgents(configPath)
+	} else {
+		agents, err = providers.LoadDefaultAgents()
+	}
+	if err != nil {
+		return providers.Agent{}, fmt.Errorf("failed to load agents config: %w", err)
+	}
+
+	agent, ok := agents[name]
+	if !ok {
+		return providers.Agent{}, fmt.Errorf("agent %q not found (check agents.yaml)", name)
+	}
+	return agent, nil
+}
+
+// resolveAgentProvider returns the named provider, pointing it at dbPath
+// when it supports a custom database location.
+func resolveAgentProvider(providerName, dbPath string) (providers.Provider, error) {
+	provider := providers.GetProvider(providerName)
+	if provider == nil {
+		return nil, fmt.Errorf("unknown provider: %s", providerName)
+	}
+	if cp, ok := provider.(*providers.CrushProvider); ok && dbPath != "" {
+		cp.SetDBPath(dbPath)
+	}
+	return provider, nil
+}
+
+func newAgentSessionCmd() *cobra.Command {
+	var providerName string
+	var dbPath string
+	var agentName string
+	var configPath string
+	var prompt string
+
+	cmd := &cobra.Command{
+		Use:   "new",
+		Short: "Start a new session bound to an agent",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if agentName == "" {
+				return fmt.Errorf("--agent is required")
+			}
+			if prompt == "" {
+				return fmt.Errorf("--prompt is required")
+			}
+
+			agent, err := resolveAgent(configPath, agentName)
+			if err != nil {
+				return err
+			}
+
+			provider, err := resolveAgentProvider(providerName, dbPath)
+			if err != nil {
+				return err
+			}
+
+			session, err := provider.NewSessionWithAgent(agent, prompt)
+			if err != nil {
+				return fmt.Errorf("failed to start session: %w", err)
+			}
+
+			fmt.Printf("✅ Started session %s with agent %q\n", session.ID, agent.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&providerName, "provider", "crush", "Provider to create the session in")
+	cmd.Flags().StringVar(&dbPath, "db", ".crush/crush.db", "Path to sqlite database (for Crush provider)")
+	cmd.Flags().StringVar(&agentName, "agent", "", "Name of the agent persona to bind to the session (required)")
+	cmd.Flags().StringVar(&configPath, "agents-config", "", "Path to agents.yaml (defaults to the user config directory)")
+	cmd.Flags().StringVar(&prompt, "prompt", "", "Initial user prompt (required)")
+
+	return cmd
+}
+
+func replyAgentSessionCmd() *cobra.Command {
+	var providerName string
+	var dbPath string
+	var agentName string
+	var configPath string
+	var sessionID string
+	var message string
+
+	cmd := &cobra.Command{
+		Use:   "reply",
+		Short: "Continue an existing session as an agent",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if agentName == "" {
+				return fmt.Errorf("--agent is required")
+			}
+			if sessionID == "" {
+				return fmt.Errorf("--session is required")
+			}
+			if message == "" {
+				return fmt.Errorf("--message is required")
+			}
+
+			agent, err := resolveAgent(configPath, agentName)
+			if err != nil {
+				return err
+			}
+
+			provider, err := resolveAgentProvider(providerName, dbPath)
+			if err != nil {
+				return err
+			}
+
+			messages, err := provider.ReplyWithAgent(sessionID, agent, message)
+			if err != nil {
+				return fmt.Errorf("failed to reply: %w", err)
+			}
+
+			fmt.Printf("✅ Session %s now has %d message(s)\n", sessionID, len(messages))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&providerName, "provider", "crush", "Provider the session belongs to")
+	cmd.Flags().StringVar(&dbPath, "db", ".crush/crush.db", "Path to sqlite database (for Crush provider)")
+	cmd.Flags().StringVar(&agentName, "agent", "", "Name of the agent persona to reply as (required)")
+	cmd.Flags().StringVar(&configPath, "agents-config", "", "Path to agents.yaml (defaults to the user config directory)")
+	cmd.Flags().StringVar(&sessionID, "session", "", "Session ID to reply to (required)")
+	cmd.Flags().StringVar(&message, "message", "", "Message to append (required)")
+
+	return cmd
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// BranchCmd creates the branch command, which groups subcommands for
+// forking a session's message history and listing the branches created so
+// far. Export a specific branch with "export --branch <id>".
+func BranchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "branch",
+		Short: "Fork a session's history or list its branches",
+		Long: `A branch lets you edit any past message and continue the conversation
+down a different path, without losing the original history. Create one
+with "branch create", see what's been forked with "branch list", then
+export a particular branch with "export --session <id> --branch <branch>".`,
+	}
+
+	cmd.AddCommand(createBranchCmd())
+	cmd.AddCommand(listBranchesCmd())
+
+	return cmd
+}
+
+func createBranchCmd() *cobra.Command {
+	var providerName string
+	var dbPath string
+	var sessionID string
+	var fromMessageID string
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Fork a session at a given message",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if sessionID == "" {
+				return fmt.Errorf("--session is required")
+			}
+			if fromMessageID == "" {
+				return fmt.Errorf("--from-message is required")
+			}
+
+			provider, err := resolveAgentProvider(providerName, dbPath)
+			if err != nil {
+				return err
+			}
+
+			branchID, err := provider.CreateBranch(sessionID, fromMessageID)
+			if err != nil {
+				return fmt.Errorf("failed to create branch: %w", err)
+			}
+
+			fmt.Printf("✅ Created branch %s from message %s\n", branchID, fromMessageID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&providerName, "provider", "crush", "Provider the session belongs to")
+	cmd.Flags().StringVar(&dbPath, "db", ".crush/crush.db", "Path to sqlite database (for Crush provider)")
+	cmd.Flags().StringVar(&sessionID, "session", "", "Session ID to fork (required)")
+	cmd.Flags().StringVar(&fromMessageID, "from-message", "", "ID of the message to fork from (required)")
+
+	return cmd
+}
+
+func listBranchesCmd() *cobra.Command {
+	var providerName string
+	var dbPath string
+	var sessionID string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the branches forked from a session",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if sessionID == "" {
+				return fmt.Errorf("--session is required")
+			}
+
+			provider, err := resolveAgentProvider(providerName, dbPath)
+			if err != nil {
+				return err
+			}
+
+			branches, err := provider.ListBranches(sessionID)
+			if err != nil {
+				return fmt.Errorf("failed to list branches: %w", err)
+			}
+
+			if len(branches) == 0 {
+				fmt.Println("No branches found for this session.")
+				return nil
+			}
+
+			for _, b := range branches {
+				title := ""
+				if b.Title != nil {
+					title = *b.Title
+				}
+				fmt.Printf("%s\tforked from %s", b.ID, b.ParentMessageID)
+				if title != "" {
+					fmt.Printf("\t%s", title)
+				}
+				fmt.Println()
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&providerName, "provider", "crush", "Provider the session belongs to")
+	cmd.Flags().StringVar(&dbPath, "db", ".crush/crush.db", "Path to sqlite database (for Crush provider)")
+	cmd.Flags().StringVar(&sessionID, "session", "", "Session ID to list branches for (required)")
+
+	return cmd
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// CompletionCmd creates the completion command, which emits a shell
+// completion script for the requested shell to stdout.
+func CompletionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion [bash|zsh|fish|powershell]",
+		Short: "Generate shell completion script",
+		Long: `To load completions:
+
+Bash:
+  $ source <(crush-md completion bash)
+
+  # To load completions for each session, add to your ~/.bashrc or ~/.bash_profile:
+  $ crush-md completion bash > /etc/bash_completion.d/crush-md
+
+Zsh:
+  $ source <(crush-md completion zsh)
+
+  # To load completions for each session, add to your ~/.zshrc:
+  $ crush-md completion zsh > "${fpath[1]}/_crush-md"
+
+Fish:
+  $ crush-md completion fish | source
+
+  # To load completions for each session:
+  $ crush-md completion fish > ~/.config/fish/completions/crush-md.fish
+
+PowerShell:
+  PS> crush-md completion powershell | Out-String | Invoke-Expression
+
+  # To load completions for every new session, run:
+  PS> crush-md completion powershell > crush-md.ps1
+  # and source this file from your PowerShell profile.
+`,
+		DisableFlagsInUseLine: true,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch args[0] {
+			case "bash":
+				return cmd.Root().GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return cmd.Root().GenZshCompletion(os.Stdout)
+			case "fish":
+				return cmd.Root().GenFishCompletion(os.Stdout, true)
+			case "powershell":
+				return cmd.Root().GenPowerShellCompletionWithDesc(os.Stdout)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -2,14 +2,20 @@ package cli
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"crush-session-explorer/internal/db"
 	"crush-session-explorer/internal/markdown"
@@ -82,6 +88,15 @@ func ExportCmd() *cobra.Command {
 	var outputPath string
 	var format string
 	var providerName string
+	var timeout time.Duration
+	var indexMode bool
+	var allMode bool
+	var since string
+	var until string
+	var concurrency int
+	var reportFormat string
+	var sshTarget string
+	var branchID string
 
 	cmd := &cobra.Command{
 		Use:   "export",
@@ -91,13 +106,30 @@ func ExportCmd() *cobra.Command {
 			// Check if format was explicitly provided
 			formatExplicit := cmd.Flags().Changed("format")
 
+			// Cancel cleanly on SIGINT/SIGTERM, and bound the session-listing
+			// scan and the session/message fetches below with --timeout
+			// (counted from here) so a large Claude state.db can't hang
+			// forever. Interactive prompts below aren't context-bound.
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = providers.WithDeadline(ctx, timeout)
+				defer cancel()
+			}
+
 			var selectedProvider providers.Provider
 			var allSessions []db.Session
 			var providerMap map[string]providers.Provider // Maps session ID to provider
 
 			// If provider is specified, use only that provider
 			if providerName != "" {
-				provider := providers.GetProvider(providerName)
+				var provider providers.Provider
+				if providerName == "ssh" {
+					provider = providers.GetProviderWithConfig("ssh", map[string]string{"target": sshTarget})
+				} else {
+					provider = providers.GetProvider(providerName)
+				}
 				if provider == nil {
 					return fmt.Errorf("unknown provider: %s", providerName)
 				}
@@ -114,7 +146,7 @@ func ExportCmd() *cobra.Command {
 				}
 
 				selectedProvider = provider
-				sessions, err := provider.ListSessions(50)
+				sessions, err := provider.ListSessionsCtx(ctx, 50)
 				if err != nil {
 					return fmt.Errorf("failed to list sessions from %s: %w", providerName, err)
 				}
@@ -148,7 +180,7 @@ func ExportCmd() *cobra.Command {
 				// Collect sessions from all providers
 				providerMap = make(map[string]providers.Provider)
 				for _, provider := range availableProviders {
-					sessions, err := provider.ListSessions(50)
+					sessions, err := provider.ListSessionsCtx(ctx, 50)
 					if err != nil {
 						fmt.Fprintf(os.Stderr, "Warning: failed to list sessions from %s: %v\n", provider.Name(), err)
 						continue
@@ -168,6 +200,61 @@ func ExportCmd() *cobra.Command {
 				}
 			}
 
+			// providerFor resolves which provider owns a given session ID,
+			// shared by --index and --all (both bypass the interactive
+			// single-session flow below and need it for every session they
+			// touch instead of just one).
+			providerFor := func(id string) (providers.Provider, error) {
+				if selectedProvider != nil {
+					return selectedProvider, nil
+				}
+				if providerMap != nil {
+					if p := providerMap[id]; p != nil {
+						return p, nil
+					}
+				}
+				return nil, fmt.Errorf("no provider found for session %s", id)
+			}
+
+			// --index ignores --session entirely: export every session this
+			// invocation discovered as its own HTML file, linked together by
+			// an index.html, instead of prompting for one session to export.
+			if indexMode {
+				dir := outputPath
+				if dir == "" {
+					dir = "session-archive"
+				}
+				return exportSessionArchive(ctx, dir, allSessions, providerFor)
+			}
+
+			// --all is the non-interactive batch path: every discovered
+			// session (optionally narrowed by --since/--until) is rendered
+			// and written under {out}/{provider}/ by a worker pool, with no
+			// prompts of any kind, so it can run unattended from a Makefile
+			// or cron job.
+			if allMode {
+				format, err := normalizeExportFormat(format)
+				if err != nil {
+					return err
+				}
+
+				sinceTime, err := parseFilterTime(since)
+				if err != nil {
+					return err
+				}
+				untilTime, err := parseFilterTime(until)
+				if err != nil {
+					return err
+				}
+
+				dir := outputPath
+				if dir == "" {
+					dir = "exports"
+				}
+
+				return runBatchExport(ctx, dir, format, reportFormat, concurrency, allSessions, providerFor, sinceTime, untilTime)
+			}
+
 			// If no session ID provided, show interactive selection
 			if sessionID == "" {
 				if len(allSessions) == 0 {
@@ -189,7 +276,13 @@ func ExportCmd() *cobra.Command {
 					// Get provider name from metadata or map
 					provider := ""
 					if s.Metadata != nil && *s.Metadata != "" {
-						provider = *s.Metadata
+						var meta map[string]string
+						if err := json.Unmarshal([]byte(*s.Metadata), &meta); err == nil {
+							provider = meta["provider"]
+							if agent := meta["agent"]; agent != "" {
+								provider = fmt.Sprintf("%s/%s", provider, agent)
+							}
+						}
 					} else if providerMap != nil {
 						if p := providerMap[s.ID]; p != nil {
 							provider = p.Name()
@@ -238,17 +331,29 @@ func ExportCmd() *cobra.Command {
 			}
 
 			// Fetch session using the selected provider
-			session, err := selectedProvider.FetchSession(sessionID)
+			session, err := selectedProvider.FetchSessionCtx(ctx, sessionID)
 			if err != nil {
 				return fmt.Errorf("failed to fetch session: %w", err)
 			}
 
-			// Fetch messages using the selected provider
-			messages, err := selectedProvider.ListMessages(session.ID)
+			// Fetch messages using the selected provider, restricted to
+			// --branch when given; branches is fetched regardless so the
+			// renderer can show what's available even when exporting main.
+			var messages []db.ParsedMessage
+			if branchID != "" {
+				messages, err = selectedProvider.ListMessagesOnBranch(session.ID, branchID)
+			} else {
+				messages, err = selectedProvider.ListMessagesCtx(ctx, session.ID)
+			}
 			if err != nil {
 				return fmt.Errorf("failed to fetch messages: %w", err)
 			}
 
+			branches, err := selectedProvider.ListBranches(session.ID)
+			if err != nil {
+				return fmt.Errorf("failed to list branches: %w", err)
+			}
+
 			// Set session content as JSON for compatibility
 			if len(messages) > 0 {
 				contentBytes, _ := json.Marshal(messages)
@@ -261,8 +366,10 @@ func ExportCmd() *cobra.Command {
 				fmt.Println("Choose export format:")
 				fmt.Println("1. Markdown (.md)")
 				fmt.Println("2. HTML with interactive panels (.html)")
+				fmt.Println("3. JSON (.json)")
+				fmt.Println("4. JSON Lines, one message per line (.jsonl)")
 
-				fmt.Print("Select format [1-2]: ")
+				fmt.Print("Select format [1-4]: ")
 				reader := bufio.NewReader(os.Stdin)
 				input, err := reader.ReadString('\n')
 				if err != nil {
@@ -275,27 +382,32 @@ func ExportCmd() *cobra.Command {
 					format = "markdown"
 				case "2":
 					format = "html"
+				case "3":
+					format = "json"
+				case "4":
+					format = "jsonl"
 				default:
-					return fmt.Errorf("invalid choice: %s (choose 1 or 2)", choice)
+					return fmt.Errorf("invalid choice: %s (choose 1-4)", choice)
 				}
 			} else {
-				// Validate format when explicitly provided
-				if format != "markdown" && format != "html" && format != "md" {
-					return fmt.Errorf("invalid format: %s (supported: markdown, html, md)", format)
-				}
-
-				// Normalize format
-				if format == "md" {
-					format = "markdown"
+				var err error
+				format, err = normalizeExportFormat(format)
+				if err != nil {
+					return err
 				}
 			}
 
 			// Generate output path if not provided
 			if outputPath == "" {
 				var filename string
-				if format == "html" {
+				switch format {
+				case "html":
 					filename = markdown.GenerateHTMLFilename(session)
-				} else {
+				case "json":
+					filename = markdown.GenerateJSONFilename(session)
+				case "jsonl":
+					filename = markdown.GenerateJSONLFilename(session)
+				default:
 					filename = markdown.GenerateFilename(session)
 				}
 				defaultDir := ".crush/sessions"
@@ -317,11 +429,9 @@ func ExportCmd() *cobra.Command {
 			}
 
 			// Render content based on format
-			var content string
-			if format == "html" {
-				content = markdown.RenderHTML(session, messages)
-			} else {
-				content = markdown.RenderMarkdown(session, messages)
+			content, err := renderExportContent(format, session, messages, branches)
+			if err != nil {
+				return fmt.Errorf("failed to render %s: %w", format, err)
 			}
 
 			// Ensure output directory exists
@@ -346,8 +456,374 @@ func ExportCmd() *cobra.Command {
 	cmd.Flags().StringVar(&dbPath, "db", ".crush/crush.db", "Path to sqlite database (for Crush provider)")
 	cmd.Flags().StringVar(&sessionID, "session", "", "Session ID to export")
 	cmd.Flags().StringVar(&outputPath, "out", "", "Output file path")
-	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown, html, md (interactive selection if not specified)")
-	cmd.Flags().StringVar(&providerName, "provider", "", "AI code tool provider: crush, claude-code (auto-detect if not specified)")
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown, html, json, jsonl, md (interactive selection if not specified)")
+	cmd.Flags().StringVar(&providerName, "provider", "", "AI code tool provider: crush, claude-code, cursor, ssh (auto-detect if not specified)")
+	cmd.Flags().StringVar(&sshTarget, "ssh", "", "With --provider ssh, the remote database as user@host:path (copied locally over SFTP before exporting)")
+	cmd.Flags().StringVar(&branchID, "branch", "", "Export a specific branch (see 'branch list') instead of the session's main history")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Bound session listing and fetching to this duration from start (e.g. 30s); 0 disables the deadline")
+	cmd.Flags().BoolVar(&indexMode, "index", false, "Export every discovered session as linked HTML pages with an index.html (ignores --session; --out sets the output directory)")
+	cmd.Flags().BoolVar(&allMode, "all", false, "Non-interactively export every discovered session (ignores --session and all prompts; --out sets the output directory)")
+	cmd.Flags().StringVar(&since, "since", "", "With --all, only export sessions created at or after this time (YYYY-MM-DD or RFC3339)")
+	cmd.Flags().StringVar(&until, "until", "", "With --all, only export sessions created at or before this time (YYYY-MM-DD or RFC3339)")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 4, "With --all, number of sessions to render concurrently")
+	cmd.Flags().StringVar(&reportFormat, "report", "text", "With --all, summary format: text, json")
+
+	registerProviderCompletion(cmd, "provider")
+	_ = cmd.RegisterFlagCompletionFunc("session", sessionIDCompletionFunc)
 
 	return cmd
 }
+
+// sessionIDCompletionFunc completes --session by listing session IDs across
+// every auto-discovered provider, so the hardest argument to type by hand
+// gets tab-completion instead of requiring a separate "export" run just to
+// see the list.
+func sessionIDCompletionFunc(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var completions []string
+	for _, provider := range providers.DiscoverAllProviders() {
+		sessions, err := provider.ListSessions(50)
+		if err != nil {
+			continue
+		}
+		for _, session := range sessions {
+			title := session.ID
+			if session.Title != nil && *session.Title != "" {
+				title = *session.Title
+			}
+			completions = append(completions, fmt.Sprintf("%s\t%s [%s]", session.ID, title, provider.Name()))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// registerProviderCompletion completes flagName against the set of
+// registered provider names (see providers.GetProvider).
+func registerProviderCompletion(cmd *cobra.Command, flagName string) {
+	_ = cmd.RegisterFlagCompletionFunc(flagName, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"crush", "claude-code", "cursor", "ssh"}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// exportSessionArchive renders one HTML file per session plus an index.html
+// linking them, each session's page pointing at its prev/next neighbour
+// (sorted by CreatedAt) so the archive can be browsed like a static blog.
+// providerFor resolves which provider owns a given session ID.
+func exportSessionArchive(ctx context.Context, outputDir string, sessions []db.Session, providerFor func(id string) (providers.Provider, error)) error {
+	if len(sessions) == 0 {
+		return fmt.Errorf("no sessions found")
+	}
+
+	sorted := make([]db.Session, len(sessions))
+	copy(sorted, sessions)
+	sort.Slice(sorted, func(i, j int) bool {
+		ti, tj := parseSessionTime(sorted[i].CreatedAt), parseSessionTime(sorted[j].CreatedAt)
+		if ti == nil || tj == nil {
+			return sorted[i].ID < sorted[j].ID
+		}
+		return ti.Before(*tj)
+	})
+
+	type page struct {
+		session  *db.Session
+		messages []db.ParsedMessage
+		filename string
+	}
+	pages := make([]page, 0, len(sorted))
+
+	for _, s := range sorted {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("export cancelled: %w", err)
+		}
+
+		provider, err := providerFor(s.ID)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", s.ID, err)
+			continue
+		}
+
+		session, err := provider.FetchSessionCtx(ctx, s.ID)
+		if err != nil {
+			fmt.Printf("❌ %s: failed to fetch session: %v\n", s.ID, err)
+			continue
+		}
+		messages, err := provider.ListMessagesCtx(ctx, s.ID)
+		if err != nil {
+			fmt.Printf("❌ %s: failed to fetch messages: %v\n", s.ID, err)
+			continue
+		}
+
+		// Carry the messages on Content so RenderIndexHTML can derive a
+		// model/provider summary and first-user-message preview per row,
+		// same compatibility convention as the single-session export path.
+		if len(messages) > 0 {
+			contentBytes, _ := json.Marshal(messages)
+			contentStr := string(contentBytes)
+			session.Content = &contentStr
+		}
+
+		pages = append(pages, page{session: session, messages: messages, filename: markdown.GenerateHTMLFilename(session)})
+	}
+
+	if len(pages) == 0 {
+		return fmt.Errorf("no sessions could be exported")
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for i, p := range pages {
+		nav := markdown.NavLinks{IndexHref: "index.html"}
+		if i > 0 {
+			nav.PrevHref = pages[i-1].filename
+			nav.PrevLabel = sessionNavLabel(pages[i-1].session)
+		}
+		if i < len(pages)-1 {
+			nav.NextHref = pages[i+1].filename
+			nav.NextLabel = sessionNavLabel(pages[i+1].session)
+		}
+
+		content := markdown.RenderHTMLWithNav(p.session, p.messages, nil, nav)
+		if err := markdown.WriteFile(filepath.Join(outputDir, p.filename), content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", p.filename, err)
+		}
+		fmt.Printf("  ✓ %s\n", p.filename)
+	}
+
+	sessionPtrs := make([]*db.Session, len(pages))
+	for i, p := range pages {
+		sessionPtrs[i] = p.session
+	}
+
+	indexPath := filepath.Join(outputDir, "index.html")
+	if err := markdown.WriteFile(indexPath, markdown.RenderIndexHTML(sessionPtrs)); err != nil {
+		return fmt.Errorf("failed to write index.html: %w", err)
+	}
+
+	fmt.Printf("\n✅ Exported %d sessions to %s\n", len(pages), indexPath)
+	return nil
+}
+
+// normalizeExportFormat validates an explicitly-provided --format value and
+// normalizes its aliases ("md" -> "markdown"), shared by the interactive
+// single-session path and --all.
+func normalizeExportFormat(format string) (string, error) {
+	switch format {
+	case "md":
+		return "markdown", nil
+	case "markdown", "html", "json", "jsonl":
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid format: %s (supported: markdown, html, json, jsonl, md)", format)
+	}
+}
+
+// renderExportContent renders a session in the given (already-normalized)
+// format, returning bytes uniformly so both the single-session and --all
+// write paths can share one os.WriteFile call regardless of whether the
+// underlying renderer returns a string (markdown/html) or ([]byte, error)
+// (json/jsonl). branches is nil on the --all path (the single-session path
+// always passes the session's branch list); the markdown/html renderers
+// treat a nil branch list the same as "no branches exist" (json/jsonl
+// ignore branches).
+func renderExportContent(format string, session *db.Session, messages []db.ParsedMessage, branches []db.Branch) ([]byte, error) {
+	switch format {
+	case "html":
+		return []byte(markdown.RenderHTMLWithBranches(session, messages, branches)), nil
+	case "json":
+		return markdown.RenderJSON(session, messages)
+	case "jsonl":
+		return markdown.RenderJSONL(session, messages)
+	default:
+		return []byte(markdown.RenderMarkdownWithBranches(session, messages, branches)), nil
+	}
+}
+
+// parseFilterTime parses a --since/--until value as either a bare date or a
+// full RFC3339 timestamp, the two shapes a human would type on a command
+// line.
+func parseFilterTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return &t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+	return nil, fmt.Errorf("invalid time %q (use YYYY-MM-DD or RFC3339)", s)
+}
+
+// parseSessionTime parses a session's created_at, trying the Unix-epoch and
+// RFC3339 shapes the various providers store it in, for --since/--until
+// filtering.
+func parseSessionTime(ts *string) *time.Time {
+	if ts == nil || *ts == "" {
+		return nil
+	}
+	if timestamp, err := strconv.ParseInt(*ts, 10, 64); err == nil {
+		t := time.Unix(timestamp, 0)
+		return &t
+	}
+	if t, err := time.Parse(time.RFC3339, *ts); err == nil {
+		return &t
+	}
+	return nil
+}
+
+// batchResult is one row of a --all run's --report summary: the outcome of
+// exporting a single session.
+type batchResult struct {
+	SessionID string `json:"session_id"`
+	Provider  string `json:"provider"`
+	File      string `json:"file,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// runBatchExport renders every session in sessions (after applying the
+// since/until filter) through a worker pool of concurrency goroutines,
+// writing each one under outputDir/{provider}/, then prints a --report
+// summary of what was produced and what failed.
+func runBatchExport(ctx context.Context, outputDir, format, reportFormat string, concurrency int, sessions []db.Session, providerFor func(id string) (providers.Provider, error), since, until *time.Time) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var filtered []db.Session
+	for _, s := range sessions {
+		t := parseSessionTime(s.CreatedAt)
+		if since != nil && (t == nil || t.Before(*since)) {
+			continue
+		}
+		if until != nil && (t == nil || t.After(*until)) {
+			continue
+		}
+		filtered = append(filtered, s)
+	}
+
+	results := make([]batchResult, len(filtered))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, s := range filtered {
+		wg.Add(1)
+		go func(i int, s db.Session) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = exportOneSession(ctx, outputDir, format, s, providerFor)
+		}(i, s)
+	}
+
+	wg.Wait()
+
+	return printBatchReport(outputDir, reportFormat, results)
+}
+
+// exportOneSession renders and writes a single session for runBatchExport,
+// returning its outcome rather than stopping the whole batch on error.
+func exportOneSession(ctx context.Context, outputDir, format string, s db.Session, providerFor func(id string) (providers.Provider, error)) batchResult {
+	result := batchResult{SessionID: s.ID}
+
+	if err := ctx.Err(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	provider, err := providerFor(s.ID)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Provider = provider.Name()
+
+	session, err := provider.FetchSessionCtx(ctx, s.ID)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to fetch session: %v", err)
+		return result
+	}
+	messages, err := provider.ListMessagesCtx(ctx, s.ID)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to fetch messages: %v", err)
+		return result
+	}
+
+	var filename string
+	switch format {
+	case "html":
+		filename = markdown.GenerateHTMLFilename(session)
+	case "json":
+		filename = markdown.GenerateJSONFilename(session)
+	case "jsonl":
+		filename = markdown.GenerateJSONLFilename(session)
+	default:
+		filename = markdown.GenerateFilename(session)
+	}
+
+	content, err := renderExportContent(format, session, messages, nil)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to render %s: %v", format, err)
+		return result
+	}
+
+	providerDir := filepath.Join(outputDir, provider.Name())
+	if err := os.MkdirAll(providerDir, 0755); err != nil {
+		result.Error = fmt.Sprintf("failed to create output directory: %v", err)
+		return result
+	}
+
+	outPath := filepath.Join(providerDir, filename)
+	if err := os.WriteFile(outPath, []byte(content), 0644); err != nil {
+		result.Error = fmt.Sprintf("failed to write file: %v", err)
+		return result
+	}
+
+	result.File = outPath
+	return result
+}
+
+// printBatchReport prints a --all run's summary in the requested format and
+// returns an error if any session failed, so the command exits non-zero for
+// scripted callers.
+func printBatchReport(outputDir, reportFormat string, results []batchResult) error {
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+
+	if reportFormat == "json" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		for _, r := range results {
+			if r.Error != "" {
+				fmt.Printf("❌ %s [%s]: %s\n", r.SessionID, r.Provider, r.Error)
+			} else {
+				fmt.Printf("  ✓ %s\n", r.File)
+			}
+		}
+		fmt.Printf("\n✅ Exported %d/%d sessions to %s\n", len(results)-failed, len(results), outputDir)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d/%d sessions failed to export", failed, len(results))
+	}
+	return nil
+}
+
+// sessionNavLabel returns a short label for a session's prev/next nav link.
+func sessionNavLabel(session *db.Session) string {
+	if session.Title != nil && *session.Title != "" {
+		return *session.Title
+	}
+	if len(session.ID) > 8 {
+		return session.ID[:8]
+	}
+	return session.ID
+}
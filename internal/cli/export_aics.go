@@ -1,13 +1,21 @@
 package cli
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"crush-session-explorer/internal/db"
 	"crush-session-explorer/internal/interchange"
+	"crush-session-explorer/internal/providers"
 
+	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 )
 
@@ -18,6 +26,16 @@ func ExportAICSCmd() *cobra.Command {
 	var providerName string
 	var limit int
 	var individualFiles bool
+	var silent bool
+	var noProgress bool
+	var sinkType string
+	var s3Endpoint string
+	var s3Bucket string
+	var s3Region string
+	var s3Prefix string
+	var compress string
+	var outputStream string
+	var timeout time.Duration
 
 	cmd := &cobra.Command{
 		Use:   "export-aics",
@@ -34,6 +52,26 @@ Benefits:
 - Archive conversations for future reference
 - Migrate from one tool to another seamlessly`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			quiet := silent
+			showBar := !noProgress && !silent
+
+			logf := func(format string, a ...interface{}) {
+				if !quiet {
+					fmt.Printf(format, a...)
+				}
+			}
+
+			// Stop between sessions on SIGINT/SIGTERM and report how far the
+			// export got; files already written (including a partial
+			// single-file archive) are left on disk.
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = providers.WithDeadline(ctx, timeout)
+				defer cancel()
+			}
+
 			// Connect to database
 			database, err := db.Connect(dbPath)
 			if err != nil {
@@ -42,7 +80,7 @@ Benefits:
 			defer database.Close()
 
 			// Fetch sessions
-			sessions, err := db.ListSessions(database, limit)
+			sessions, err := db.ListSessionsCtx(ctx, database, limit)
 			if err != nil {
 				return fmt.Errorf("failed to list sessions: %w", err)
 			}
@@ -51,46 +89,161 @@ Benefits:
 				return fmt.Errorf("no sessions found in database")
 			}
 
-			fmt.Printf("Found %d sessions to export\n", len(sessions))
-
-			// Fetch messages for each session
-			messagesMap := make(map[string][]db.ParsedMessage)
-			for _, session := range sessions {
-				messages, err := db.ListMessages(database, session.ID)
-				if err != nil {
-					return fmt.Errorf("failed to fetch messages for session %s: %w", session.ID, err)
-				}
-				messagesMap[session.ID] = messages
-			}
+			logf("Found %d sessions to export\n", len(sessions))
 
 			// Get or generate client ID
 			clientID, err := interchange.GetClientID()
 			if err != nil {
-				fmt.Printf("⚠️  Warning: Failed to get client ID: %v\n", err)
+				logf("⚠️  Warning: Failed to get client ID: %v\n", err)
 				clientID = ""
 			} else {
-				fmt.Printf("📱 Client ID: %s\n", clientID)
+				logf("📱 Client ID: %s\n", clientID)
+			}
+
+			// NDJSON streaming mode: write one session per line so this
+			// command's output can be piped straight into
+			// "import-aics --input - --format markdown" without either side
+			// buffering the whole archive.
+			if outputStream != "" {
+				var dest *os.File
+				if outputStream == "-" {
+					dest = os.Stdout
+				} else {
+					dest, err = os.Create(outputStream)
+					if err != nil {
+						return fmt.Errorf("failed to create output file: %w", err)
+					}
+					defer dest.Close()
+				}
+
+				sw := interchange.NewStreamWriter(dest)
+				for _, session := range sessions {
+					if err := ctx.Err(); err != nil {
+						return fmt.Errorf("export cancelled: %w", err)
+					}
+
+					messages, err := db.ListMessagesCtx(ctx, database, session.ID)
+					if err != nil {
+						return fmt.Errorf("failed to fetch messages for session %s: %w", session.ID, err)
+					}
+
+					aicsSession, err := interchange.ConvertSessionForStream(session, messages)
+					if err != nil {
+						return fmt.Errorf("failed to convert session %s: %w", session.ID, err)
+					}
+					if clientID != "" {
+						aicsSession.ClientID = clientID
+					}
+					if err := sw.WriteSession(*aicsSession); err != nil {
+						return fmt.Errorf("failed to write session %s: %w", session.ID, err)
+					}
+				}
+				if err := sw.Close(); err != nil {
+					return fmt.Errorf("failed to close AICS stream: %w", err)
+				}
+
+				logf("✅ Streamed %d sessions to %s\n", len(sessions), outputStream)
+				return nil
+			}
+
+			var bar *progressbar.ProgressBar
+			if showBar {
+				bar = progressbar.NewOptions(len(sessions),
+					progressbar.OptionSetDescription("Exporting sessions"),
+					progressbar.OptionShowCount(),
+					progressbar.OptionOnCompletion(func() { fmt.Println() }),
+				)
+			}
+			exported := 0
+			progress := func(done, total int) {
+				exported = done
+				if bar != nil {
+					_ = bar.Set(done)
+				}
+			}
+
+			reportAbort := func(done int) error {
+				if bar != nil {
+					_ = bar.Finish()
+				}
+				logf("⏹  Cancelled after exporting %d/%d sessions\n", done, len(sessions))
+				return fmt.Errorf("export cancelled: %w", ctx.Err())
 			}
 
 			// Export based on mode
-			if individualFiles {
+			if sinkType == "s3" {
+				// Object-storage sinks only make sense per-session: upload
+				// each converted session as its own object under its
+				// YYYY/MM/DD/ key, no local staging directory involved.
+				messagesMap, err := fetchAllMessages(ctx, database, sessions)
+				if err != nil {
+					return err
+				}
+
+				accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+				if accessKey == "" {
+					accessKey = os.Getenv("CRUSH_S3_ACCESS_KEY")
+				}
+				secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+				if secretKey == "" {
+					secretKey = os.Getenv("CRUSH_S3_SECRET_KEY")
+				}
+
+				if s3Endpoint == "" || s3Bucket == "" {
+					return fmt.Errorf("--sink=s3 requires --s3-endpoint and --s3-bucket")
+				}
+
+				sink, err := interchange.NewS3Sink(interchange.S3SinkConfig{
+					Endpoint:     s3Endpoint,
+					Bucket:       s3Bucket,
+					Region:       s3Region,
+					Prefix:       s3Prefix,
+					AccessKey:    accessKey,
+					SecretKey:    secretKey,
+					UseSSL:       true,
+					ProviderName: providerName,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to set up S3 sink: %w", err)
+				}
+				defer sink.Close()
+
+				keys, err := interchange.ExportSessionsToSinkCtx(ctx, sink, sessions, messagesMap, clientID, progress)
+				if err != nil {
+					if ctx.Err() != nil {
+						return reportAbort(len(keys))
+					}
+					return fmt.Errorf("failed to export sessions to s3://%s: %w", s3Bucket, err)
+				}
+
+				logf("✅ Uploaded %d sessions to s3://%s/%s\n", len(keys), s3Bucket, s3Prefix)
+				logf("📊 Format: AICS v%s (AI Coding Session Interchange Format)\n", interchange.FormatVersion)
+			} else if individualFiles {
 				// Export each session to its own file in date-based folders
 				if outputPath == "" {
 					outputPath = "sessions"
 				}
 
-				exportedFiles, err := interchange.ExportSessionsIndividually(sessions, messagesMap, outputPath, providerName, clientID)
+				messagesMap, err := fetchAllMessages(ctx, database, sessions)
+				if err != nil {
+					return err
+				}
+
+				exportedFiles, err := interchange.ExportSessionsIndividuallyCtx(ctx, sessions, messagesMap, outputPath, providerName, clientID, progress)
 				if err != nil {
+					if ctx.Err() != nil {
+						return reportAbort(len(exportedFiles))
+					}
 					return fmt.Errorf("failed to export sessions: %w", err)
 				}
 
-				fmt.Printf("✅ Exported %d sessions to individual files in %s\n", len(exportedFiles), outputPath)
-				fmt.Printf("📊 Format: AICS v%s (AI Coding Session Interchange Format)\n", interchange.FormatVersion)
-				fmt.Printf("📁 Sessions organized by date: YYYY/MM/DD/\n")
-				fmt.Printf("💡 Each session has a unique UUID v7 identifier\n")
+				logf("✅ Exported %d sessions to individual files in %s\n", len(exportedFiles), outputPath)
+				logf("📊 Format: AICS v%s (AI Coding Session Interchange Format)\n", interchange.FormatVersion)
+				logf("📁 Sessions organized by date: YYYY/MM/DD/\n")
+				logf("💡 Each session has a unique UUID v7 identifier\n")
 
 				// Show first few files as examples
-				if len(exportedFiles) > 0 {
+				if len(exportedFiles) > 0 && !quiet {
 					fmt.Printf("\n📄 Example files:\n")
 					showCount := 3
 					if len(exportedFiles) < showCount {
@@ -105,36 +258,49 @@ Benefits:
 					}
 				}
 			} else {
-				// Export all sessions to a single file (original behavior)
-				archive, err := interchange.ExportToAICS(sessions, messagesMap, providerName)
-				if err != nil {
-					return fmt.Errorf("failed to export to AICS: %w", err)
-				}
-
-				// Convert to JSON
-				jsonData, err := archive.ToJSON()
-				if err != nil {
-					return fmt.Errorf("failed to convert to JSON: %w", err)
-				}
+				// Export all sessions to a single file, streaming session-by-session
+				// rather than building the whole Archive in memory first.
+				compression := interchange.Compression(compress)
 
-				// Generate output path if not provided
 				if outputPath == "" {
 					outputPath = "sessions.aics.json"
 				}
+				if ext := compression.Extension(); ext != "" && !strings.HasSuffix(outputPath, ext) {
+					outputPath += ext
+				}
 
 				// Ensure output directory exists
 				if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 					return fmt.Errorf("failed to create output directory: %w", err)
 				}
 
-				// Write file
-				if err := os.WriteFile(outputPath, jsonData, 0644); err != nil {
-					return fmt.Errorf("failed to write output file: %w", err)
+				file, err := os.Create(outputPath)
+				if err != nil {
+					return fmt.Errorf("failed to create output file: %w", err)
+				}
+				defer file.Close()
+
+				// Fetch each session's messages on demand as the stream writer
+				// reaches it, rather than materializing every session's
+				// messages in memory up front.
+				fetch := func(id string) ([]db.ParsedMessage, error) {
+					return db.ListMessagesCtx(ctx, database, id)
 				}
 
-				fmt.Printf("✅ Exported %d sessions to %s\n", len(sessions), outputPath)
-				fmt.Printf("📊 Format: AICS v%s (AI Coding Session Interchange Format)\n", interchange.FormatVersion)
-				fmt.Printf("💡 This file can be imported into other AI coding tools that support AICS\n")
+				err = interchange.WriteAICSStreamCtx(ctx, file, sessions, fetch, interchange.StreamOptions{
+					ProviderName: providerName,
+					Compression:  compression,
+				}, progress)
+				if err != nil {
+					if ctx.Err() != nil {
+						return reportAbort(exported)
+					}
+					return fmt.Errorf("failed to export to AICS: %w", err)
+				}
+
+				logf("✅ Exported %d sessions to %s\n", len(sessions), outputPath)
+				logf("📊 Format: AICS v%s (AI Coding Session Interchange Format)\n", interchange.FormatVersion)
+				logf("💡 This file can be imported into other AI coding tools that support AICS\n")
 			}
 
 			return nil
@@ -144,8 +310,36 @@ Benefits:
 	cmd.Flags().StringVar(&dbPath, "db", ".crush/crush.db", "Path to sqlite database")
 	cmd.Flags().StringVar(&outputPath, "out", "", "Output path (file for single, directory for individual)")
 	cmd.Flags().StringVar(&providerName, "provider", "Crush", "Name of the AI provider/tool")
+	cmd.Flags().BoolVar(&silent, "silent", false, "Suppress all non-error output")
+	cmd.Flags().BoolVar(&noProgress, "no-progress", false, "Disable the progress bar (scripted usage)")
 	cmd.Flags().IntVar(&limit, "limit", 50, "Maximum number of sessions to export")
 	cmd.Flags().BoolVar(&individualFiles, "individual", false, "Export each session to its own file in YYYY/MM/DD/ folders")
+	cmd.Flags().StringVar(&sinkType, "sink", "fs", "Archive sink: fs, s3 (credentials via AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY)")
+	cmd.Flags().StringVar(&s3Endpoint, "s3-endpoint", "", "S3-compatible endpoint (e.g. s3.amazonaws.com, or a MinIO/GCS host)")
+	cmd.Flags().StringVar(&s3Bucket, "s3-bucket", "", "S3 bucket to upload sessions to")
+	cmd.Flags().StringVar(&s3Region, "s3-region", "us-east-1", "S3 region")
+	cmd.Flags().StringVar(&s3Prefix, "s3-prefix", "", "Key prefix for uploaded session objects")
+	cmd.Flags().StringVar(&compress, "compress", "none", "Compress single-file output: none, gzip, zstd (adds .gz/.zst to --out)")
+	cmd.Flags().StringVar(&outputStream, "output", "", "Stream NDJSON AICS output to this path, or \"-\" for stdout (for piping into import-aics --input -)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Bound the whole export to this duration (e.g. 5m); 0 disables the deadline")
 
 	return cmd
 }
+
+// fetchAllMessages eagerly loads every session's messages up front, for the
+// sinks (s3, individual files) that need the whole map in hand rather than
+// fetching lazily as they go.
+func fetchAllMessages(ctx context.Context, database *sql.DB, sessions []db.Session) (map[string][]db.ParsedMessage, error) {
+	messagesMap := make(map[string][]db.ParsedMessage)
+	for _, session := range sessions {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("export cancelled: %w", err)
+		}
+		messages, err := db.ListMessagesCtx(ctx, database, session.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch messages for session %s: %w", session.ID, err)
+		}
+		messagesMap[session.ID] = messages
+	}
+	return messagesMap, nil
+}
@@ -1,11 +1,22 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
 
+	"crush-session-explorer/internal/db"
 	"crush-session-explorer/internal/interchange"
 	"crush-session-explorer/internal/markdown"
+	"crush-session-explorer/internal/providers"
 
 	"github.com/spf13/cobra"
 )
@@ -15,22 +26,56 @@ func ImportAICSCmd() *cobra.Command {
 	var inputPath string
 	var outputDir string
 	var format string
+	var dbPath string
+	var onConflict string
+	var timeout time.Duration
 
 	cmd := &cobra.Command{
-		Use:   "import-aics",
+		Use:   "import-aics [files or directories...]",
 		Short: "Import sessions from AICS (AI Coding Session) interchange format",
 		Long: `Import sessions from the AICS standard interchange format.
 
 The AICS format is a standardized JSON format for AI coding sessions.
-This command imports AICS files and converts them to markdown or HTML format.
+By default this command converts a single AICS file to markdown or HTML
+(--input/--out/--format). Pass --db instead to merge one or more .aics.json
+files, or a directory tree of the YYYY/MM/DD/ layout produced by
+"export-aics --individual", straight into a Crush SQLite database.
 
 Use this to:
 - Import sessions from other AI coding tools
 - Migrate from another tool to your current workflow
-- Convert archived sessions to readable format`,
+- Convert archived sessions to readable format
+- Merge archived sessions back into .crush/crush.db`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// Cancel cleanly on SIGINT/SIGTERM, and bound the whole import
+			// with --timeout so merging a large batch of files can't hang
+			// forever.
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = providers.WithDeadline(ctx, timeout)
+				defer cancel()
+			}
+
+			if dbPath != "" {
+				return importToDatabase(ctx, dbPath, onConflict, args)
+			}
+
 			if inputPath == "" {
-				return fmt.Errorf("input file path is required (use --input)")
+				return fmt.Errorf("input file path is required (use --input, or --db plus one or more files/directories)")
+			}
+
+			// Validate format
+			if format != "markdown" && format != "html" && format != "md" {
+				return fmt.Errorf("invalid format: %s (supported: markdown, html, md)", format)
+			}
+			if format == "md" {
+				format = "markdown"
+			}
+
+			if inputPath == "-" {
+				return importFromStream(ctx, os.Stdin, format, outputDir)
 			}
 
 			// Import from AICS file
@@ -59,16 +104,6 @@ Use this to:
 				return fmt.Errorf("failed to convert to database format: %w", err)
 			}
 
-			// Validate format
-			if format != "markdown" && format != "html" && format != "md" {
-				return fmt.Errorf("invalid format: %s (supported: markdown, html, md)", format)
-			}
-
-			// Normalize format
-			if format == "md" {
-				format = "markdown"
-			}
-
 			// Export each session to the specified format
 			fmt.Printf("\n📤 Exporting sessions to %s format...\n", format)
 
@@ -108,11 +143,215 @@ Use this to:
 		},
 	}
 
-	cmd.Flags().StringVar(&inputPath, "input", "", "Input AICS file path (required)")
-	cmd.Flags().StringVar(&outputDir, "out", "imported-sessions", "Output directory for exported sessions")
-	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown, html, md")
-
-	cmd.MarkFlagRequired("input")
+	cmd.Flags().StringVar(&inputPath, "input", "", "Input AICS file path (markdown/html export mode)")
+	cmd.Flags().StringVar(&outputDir, "out", "imported-sessions", "Output directory for exported sessions (markdown/html export mode)")
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown, html, md (markdown/html export mode)")
+	cmd.Flags().StringVar(&dbPath, "db", "", "Path to sqlite database to merge sessions into (enables database merge mode)")
+	cmd.Flags().StringVar(&onConflict, "on-conflict", "skip", "How to handle sessions that already exist: skip, replace, merge")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Bound the whole import to this duration (e.g. 5m); 0 disables the deadline")
 
 	return cmd
 }
+
+// importFromStream reads an NDJSON AICS stream (as produced by
+// "export-aics --output -") from r and exports each session to format as it
+// arrives, so a pipeline like "export-aics --output - | import-aics --input -"
+// never buffers the whole archive on either side.
+func importFromStream(ctx context.Context, r io.Reader, format, outputDir string) error {
+	sr := interchange.NewStreamReader(r)
+
+	header, err := sr.Header()
+	if err != nil {
+		return fmt.Errorf("failed to read AICS stream: %w", err)
+	}
+	fmt.Printf("📥 Streaming import from %s v%s\n", header.Creator.Name, header.Creator.Version)
+
+	successCount, total := 0, 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("import cancelled: %w", err)
+		}
+
+		session, err := sr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read AICS stream: %w", err)
+		}
+		total++
+
+		if err := validateSessionForImport(*session); err != nil {
+			fmt.Printf("❌ Session %s: %v\n", session.ID, err)
+			continue
+		}
+
+		dbSession, messages, err := interchange.ConvertAICSSessionToDBFormat(*session)
+		if err != nil {
+			fmt.Printf("❌ Session %s: failed to convert to database format: %v\n", session.ID, err)
+			continue
+		}
+
+		var filename, content string
+		if format == "html" {
+			filename = markdown.GenerateHTMLFilename(dbSession)
+			content = markdown.RenderHTML(dbSession, messages)
+		} else {
+			filename = markdown.GenerateFilename(dbSession)
+			content = markdown.RenderMarkdown(dbSession, messages)
+		}
+
+		outputPath := filepath.Join(outputDir, filename)
+		if err := markdown.WriteFile(outputPath, content); err != nil {
+			fmt.Printf("❌ Failed to export session %s: %v\n", session.ID, err)
+			continue
+		}
+
+		successCount++
+		fmt.Printf("  ✓ %s\n", filename)
+	}
+
+	fmt.Printf("\n✅ Successfully exported %d/%d sessions to %s\n", successCount, total, outputDir)
+	return nil
+}
+
+// validateSessionForImport applies the same basic shape checks
+// ValidateArchive/ValidateStream run, against a single already-decoded
+// session, so a malformed line in the stream is skipped rather than aborting
+// the whole import.
+func validateSessionForImport(session interchange.Session) error {
+	if session.ID == "" {
+		return fmt.Errorf("missing ID")
+	}
+	if len(session.Messages) == 0 {
+		return fmt.Errorf("no messages")
+	}
+	for j, msg := range session.Messages {
+		if msg.ID == "" {
+			return fmt.Errorf("message %d: missing ID", j)
+		}
+		if msg.Role == "" {
+			return fmt.Errorf("message %s: missing role", msg.ID)
+		}
+		if len(msg.Content) == 0 {
+			return fmt.Errorf("message %s: no content", msg.ID)
+		}
+	}
+	return nil
+}
+
+// importToDatabase merges one or more .aics.json files, or directory trees
+// of them, into the database at dbPath using onConflict for any session ID
+// that already exists.
+func importToDatabase(ctx context.Context, dbPath, onConflict string, paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("--db requires at least one .aics.json file or directory argument")
+	}
+	if onConflict != "skip" && onConflict != "replace" && onConflict != "merge" {
+		return fmt.Errorf("invalid --on-conflict: %s (supported: skip, replace, merge)", onConflict)
+	}
+
+	files, err := collectAICSFiles(paths)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no .aics.json files found in %v", paths)
+	}
+	sort.Strings(files)
+
+	database, err := db.Connect(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer database.Close()
+
+	total := &db.ImportSummary{}
+	failed := 0
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("import cancelled: %w", err)
+		}
+
+		archive, err := interchange.ImportFromFile(file)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", file, err)
+			failed++
+			continue
+		}
+
+		if err := interchange.ValidateArchive(archive); err != nil {
+			fmt.Printf("❌ %s: invalid AICS file: %v\n", file, err)
+			failed++
+			continue
+		}
+
+		sessions, messagesMap, err := archive.ConvertToDBFormat()
+		if err != nil {
+			fmt.Printf("❌ %s: failed to convert to database format: %v\n", file, err)
+			failed++
+			continue
+		}
+
+		summary, err := db.ImportSessions(database, sessions, messagesMap, onConflict)
+		if err != nil {
+			fmt.Printf("❌ %s: failed to import: %v\n", file, err)
+			failed++
+			continue
+		}
+
+		total.Inserted += summary.Inserted
+		total.Skipped += summary.Skipped
+		total.Merged += summary.Merged
+		total.Errors = append(total.Errors, summary.Errors...)
+
+		fmt.Printf("  ✓ %s (%d session(s))\n", file, len(sessions))
+	}
+
+	fmt.Printf("\n📊 %d/%d file(s) imported: %d inserted, %d skipped, %d merged\n",
+		len(files)-failed, len(files), total.Inserted, total.Skipped, total.Merged)
+	for _, e := range total.Errors {
+		fmt.Printf("  ⚠️  %s\n", e)
+	}
+
+	if failed > 0 || len(total.Errors) > 0 {
+		return fmt.Errorf("%d file(s) failed to import, %d session(s) failed to merge", failed, len(total.Errors))
+	}
+
+	return nil
+}
+
+// collectAICSFiles expands a mix of file and directory paths into a flat
+// list of .aics.json files, walking directories recursively (matching the
+// YYYY/MM/DD/ layout produced by "export-aics --individual").
+func collectAICSFiles(paths []string) ([]string, error) {
+	var files []string
+
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", p, err)
+		}
+
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+
+		err = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(path, ".aics.json") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk %s: %w", p, err)
+		}
+	}
+
+	return files, nil
+}
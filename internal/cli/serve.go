@@ -0,0 +1,267 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"crush-session-explorer/internal/db"
+	"crush-session-explorer/internal/markdown"
+	"crush-session-explorer/internal/providers"
+
+	"github.com/spf13/cobra"
+)
+
+// ServeCmd creates the serve command: a small embedded HTTP server, backed
+// by the same providers.Provider interface and markdown/HTML renderers
+// "export" uses, so a user can keep a local dashboard open instead of
+// re-running "export" for every session they want to look at.
+func ServeCmd() *cobra.Command {
+	var addr string
+	var dbPath string
+	var providerName string
+	var open bool
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Browse sessions through an embedded HTTP server",
+		Long: `Serve starts a small local HTTP server backed by the same
+providers.Provider interface and renderers "export" uses:
+
+  GET /                                session list across every discovered provider
+  GET /sessions/{provider}/{id}         interactive HTML detail view
+  GET /sessions/{provider}/{id}.html    the same view, as a downloadable file
+  GET /sessions/{provider}/{id}.md      the session rendered as markdown
+
+Use --provider to restrict the server to a single provider, and --open to
+launch the browser once the server is listening.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			available, err := discoverServeProviders(providerName, dbPath)
+			if err != nil {
+				return err
+			}
+			if len(available) == 0 {
+				return fmt.Errorf("no AI code tool sessions found. Checked: Crush (.crush/crush.db), Claude Code")
+			}
+
+			srv := &sessionServer{providers: available}
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", srv.handleIndex)
+			mux.HandleFunc("/sessions/", srv.handleSession)
+
+			httpServer := &http.Server{Addr: addr, Handler: mux}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- httpServer.ListenAndServe()
+			}()
+
+			fmt.Printf("📡 Serving sessions at http://%s (Ctrl+C to stop)\n", addr)
+			if open {
+				if err := openInBrowser(fmt.Sprintf("http://%s", addr)); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  Failed to open browser: %v\n", err)
+				}
+			}
+
+			select {
+			case err := <-errCh:
+				if err != nil && err != http.ErrServerClosed {
+					return fmt.Errorf("server error: %w", err)
+				}
+				return nil
+			case <-ctx.Done():
+				fmt.Println("\n⏹  Shutting down...")
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := httpServer.Shutdown(shutdownCtx); err != nil {
+					return fmt.Errorf("failed to shut down cleanly: %w", err)
+				}
+				return nil
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:7777", "Address to serve the session browser on")
+	cmd.Flags().StringVar(&dbPath, "db", ".crush/crush.db", "Path to sqlite database (for Crush provider)")
+	cmd.Flags().StringVar(&providerName, "provider", "", "Only serve sessions from this provider (auto-detect all if not specified)")
+	cmd.Flags().BoolVar(&open, "open", false, "Open the session browser in the default web browser once listening")
+
+	return cmd
+}
+
+// discoverServeProviders resolves which providers a "serve" invocation
+// exposes: just providerName when given (matching ExportCmd's --provider
+// behavior), otherwise every provider DiscoverAllProviders finds, plus a
+// custom-path Crush provider when dbPath points somewhere non-default.
+func discoverServeProviders(providerName, dbPath string) ([]providers.Provider, error) {
+	if providerName != "" {
+		provider := providers.GetProvider(providerName)
+		if provider == nil {
+			return nil, fmt.Errorf("unknown provider: %s", providerName)
+		}
+		if cp, ok := provider.(*providers.CrushProvider); ok && dbPath != "" {
+			cp.SetDBPath(dbPath)
+		}
+		found, err := provider.Discover()
+		if err != nil || !found {
+			return nil, fmt.Errorf("provider '%s' data not found", providerName)
+		}
+		return []providers.Provider{provider}, nil
+	}
+
+	available := providers.DiscoverAllProviders()
+
+	if dbPath != "" && dbPath != ".crush/crush.db" {
+		crushProvider := providers.NewCrushProviderWithPath(dbPath)
+		if found, _ := crushProvider.Discover(); found {
+			available = append(available, crushProvider)
+		}
+	}
+
+	return available, nil
+}
+
+// sessionServer holds the providers a "serve" invocation discovered, and
+// dispatches every request against them by name.
+type sessionServer struct {
+	providers []providers.Provider
+}
+
+func (s *sessionServer) providerByName(name string) providers.Provider {
+	for _, p := range s.providers {
+		if p.Name() == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// handleIndex lists every session across every provider this server was
+// started with, linking each one into handleSession.
+func (s *sessionServer) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	var rows strings.Builder
+	for _, provider := range s.providers {
+		sessions, err := provider.ListSessionsCtx(r.Context(), 50)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to list sessions from %s: %v\n", provider.Name(), err)
+			continue
+		}
+
+		for _, session := range sessions {
+			rows.WriteString(renderSessionRow(provider.Name(), session))
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, indexPageTemplate, rows.String())
+}
+
+// renderSessionRow renders one <tr> of the session list, linking into
+// /sessions/{provider}/{id}.
+func renderSessionRow(providerName string, session db.Session) string {
+	title := session.ID
+	if session.Title != nil && *session.Title != "" {
+		title = *session.Title
+	}
+	messageCount := 0
+	if session.MessageCount != nil {
+		messageCount = *session.MessageCount
+	}
+
+	href := fmt.Sprintf("/sessions/%s/%s", url.PathEscape(providerName), url.PathEscape(session.ID))
+	return fmt.Sprintf(
+		"<tr><td>%s</td><td><a href=\"%s\">%s</a></td><td>%d</td><td>%s</td></tr>\n",
+		html.EscapeString(providerName), href, html.EscapeString(title), messageCount,
+		html.EscapeString(formatTimestamp(session.CreatedAt)),
+	)
+}
+
+const indexPageTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Crush Session Explorer</title>
+<style>
+    body { font-family: -apple-system, BlinkMacSystemFont, sans-serif; margin: 2em; color: #222; }
+    table { border-collapse: collapse; width: 100%%; }
+    th, td { text-align: left; padding: 0.4em 0.8em; border-bottom: 1px solid #eee; }
+    th { color: #666; font-weight: 600; }
+    a { color: #667eea; text-decoration: none; }
+    a:hover { text-decoration: underline; }
+</style>
+</head>
+<body>
+<h1>Sessions</h1>
+<table>
+<tr><th>Provider</th><th>Title</th><th>Messages</th><th>Created</th></tr>
+%s</table>
+</body>
+</html>`
+
+// handleSession serves a single session: the interactive HTML view at
+// /sessions/{provider}/{id}, or the same content as a downloadable
+// .html/.md file when the path carries that extension.
+func (s *sessionServer) handleSession(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/sessions/")
+	providerName, sessionID, ok := strings.Cut(rest, "/")
+	if !ok || providerName == "" || sessionID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	download := ""
+	for _, ext := range []string{".html", ".md"} {
+		if strings.HasSuffix(sessionID, ext) {
+			sessionID = strings.TrimSuffix(sessionID, ext)
+			download = ext
+			break
+		}
+	}
+
+	provider := s.providerByName(providerName)
+	if provider == nil {
+		http.Error(w, fmt.Sprintf("unknown provider: %s", providerName), http.StatusNotFound)
+		return
+	}
+
+	session, err := provider.FetchSessionCtx(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("session not found: %s", sessionID), http.StatusNotFound)
+		return
+	}
+	messages, err := provider.ListMessagesCtx(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to load messages: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	switch download {
+	case ".md":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", markdown.GenerateFilename(session)))
+		fmt.Fprint(w, markdown.RenderMarkdown(session, messages))
+	case ".html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", markdown.GenerateHTMLFilename(session)))
+		fmt.Fprint(w, markdown.RenderHTML(session, messages))
+	default:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, markdown.RenderHTML(session, messages))
+	}
+}
@@ -0,0 +1,190 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EnsureBranchSchema creates the branches/branch_messages tables used to
+// track forked message history, if they don't already exist. Unlike
+// sessions/messages, which are owned and created by Crush itself, these
+// tables belong entirely to crush-session-explorer, so it's safe to create
+// them lazily against any Crush database the first time branching is used.
+func EnsureBranchSchema(conn *sql.DB) error {
+	if _, err := conn.Exec(`CREATE TABLE IF NOT EXISTS branches (
+		id TEXT PRIMARY KEY,
+		session_id TEXT NOT NULL,
+		parent_message_id TEXT NOT NULL,
+		created_at TEXT,
+		title TEXT
+	)`); err != nil {
+		return fmt.Errorf("failed to create branches table: %w", err)
+	}
+
+	if _, err := conn.Exec(`CREATE TABLE IF NOT EXISTS branch_messages (
+		branch_id TEXT NOT NULL,
+		message_id TEXT NOT NULL,
+		ordinal INTEGER NOT NULL,
+		PRIMARY KEY (branch_id, message_id)
+	)`); err != nil {
+		return fmt.Errorf("failed to create branch_messages table: %w", err)
+	}
+
+	return nil
+}
+
+// CreateBranch forks sessionID at fromMessageID, returning the new branch's ID.
+func CreateBranch(conn *sql.DB, sessionID, fromMessageID string) (string, error) {
+	if err := EnsureBranchSchema(conn); err != nil {
+		return "", err
+	}
+
+	id := newBranchID()
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	if _, err := conn.Exec(
+		"INSERT INTO branches (id, session_id, parent_message_id, created_at) VALUES (?, ?, ?, ?)",
+		id, sessionID, fromMessageID, now,
+	); err != nil {
+		return "", fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	return id, nil
+}
+
+// ListBranches returns every branch forked from sessionID, oldest first.
+func ListBranches(conn *sql.DB, sessionID string) ([]Branch, error) {
+	if err := EnsureBranchSchema(conn); err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.Query(
+		"SELECT id, session_id, parent_message_id, created_at, title FROM branches WHERE session_id = ? ORDER BY created_at ASC",
+		sessionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query branches: %w", err)
+	}
+	defer rows.Close()
+
+	var branches []Branch
+	for rows.Next() {
+		var b Branch
+		if err := rows.Scan(&b.ID, &b.SessionID, &b.ParentMessageID, &b.CreatedAt, &b.Title); err != nil {
+			return nil, fmt.Errorf("failed to scan branch: %w", err)
+		}
+		branches = append(branches, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating branches: %w", err)
+	}
+
+	return branches, nil
+}
+
+// AppendMessageOnBranch appends msg to sessionID via AppendMessage, then
+// records it as belonging to branchID.
+func AppendMessageOnBranch(conn *sql.DB, sessionID, branchID string, msg ParsedMessage) error {
+	if err := EnsureBranchSchema(conn); err != nil {
+		return err
+	}
+
+	if err := AppendMessage(conn, sessionID, msg); err != nil {
+		return err
+	}
+
+	var ordinal int
+	if err := conn.QueryRow("SELECT COUNT(*) FROM branch_messages WHERE branch_id = ?", branchID).Scan(&ordinal); err != nil {
+		return fmt.Errorf("failed to count branch messages: %w", err)
+	}
+
+	if _, err := conn.Exec(
+		"INSERT INTO branch_messages (branch_id, message_id, ordinal) VALUES (?, ?, ?)",
+		branchID, msg.ID, ordinal,
+	); err != nil {
+		return fmt.Errorf("failed to record branch message: %w", err)
+	}
+
+	return nil
+}
+
+// ListMessagesOnBranch returns sessionID's trunk messages up through
+// branchID's fork point, followed by every message recorded on that branch,
+// in chronological order. An empty branchID returns just the trunk.
+func ListMessagesOnBranch(conn *sql.DB, sessionID, branchID string) ([]ParsedMessage, error) {
+	all, err := ListMessages(conn, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if branchID == "" {
+		return all, nil
+	}
+
+	if err := EnsureBranchSchema(conn); err != nil {
+		return nil, err
+	}
+
+	var parentMessageID string
+	err = conn.QueryRow(
+		"SELECT parent_message_id FROM branches WHERE id = ? AND session_id = ?", branchID, sessionID,
+	).Scan(&parentMessageID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("branch not found: %s", branchID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up branch: %w", err)
+	}
+
+	branchMessageIDs, err := branchMessageIDSet(conn, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ParsedMessage
+	pastFork := false
+	for _, msg := range all {
+		switch {
+		case !pastFork:
+			result = append(result, msg)
+			if msg.ID == parentMessageID {
+				pastFork = true
+			}
+		case branchMessageIDs[msg.ID]:
+			msg.BranchID = branchID
+			result = append(result, msg)
+		}
+	}
+
+	return result, nil
+}
+
+// branchMessageIDSet returns the set of message IDs recorded on branchID.
+func branchMessageIDSet(conn *sql.DB, branchID string) (map[string]bool, error) {
+	rows, err := conn.Query("SELECT message_id FROM branch_messages WHERE branch_id = ?", branchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query branch messages: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan branch message id: %w", err)
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// newBranchID generates a UUID v7 for a new branch.
+func newBranchID() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.NewString()
+	}
+	return id.String()
+}
@@ -0,0 +1,21 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Connect opens (and verifies, via Ping) a sqlite database connection at
+// path, for callers that don't already have an open *sql.DB to pass to the
+// rest of this package's functions.
+func Connect(path string) (*sql.DB, error) {
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return conn, nil
+}
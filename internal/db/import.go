@@ -0,0 +1,224 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+)
+
+// ImportSummary reports what ImportSessions did with each session in the batch.
+type ImportSummary struct {
+	Inserted int
+	Skipped  int
+	Merged   int
+	Errors   []string
+}
+
+// ImportSessions upserts sessions and their messages into the database
+// inside a single transaction, following onConflict ("skip", "replace", or
+// "merge") whenever a session ID already exists:
+//   - skip: leave the existing session and its messages untouched
+//   - replace: delete the existing session/messages and re-insert
+//   - merge: keep the existing rows, appending any messages not already
+//     present (matched by message ID, falling back to (role, timestamp,
+//     hash(parts)) when IDs collide across tools)
+func ImportSessions(conn *sql.DB, sessions []Session, messagesMap map[string][]ParsedMessage, onConflict string) (*ImportSummary, error) {
+	if onConflict != "skip" && onConflict != "replace" && onConflict != "merge" {
+		return nil, fmt.Errorf("unknown on-conflict mode: %s", onConflict)
+	}
+
+	tx, err := conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	summary := &ImportSummary{}
+
+	for _, session := range sessions {
+		messages := messagesMap[session.ID]
+
+		exists, err := sessionExists(tx, session.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check session %s: %w", session.ID, err)
+		}
+
+		if !exists {
+			if err := insertSession(tx, session, messages); err != nil {
+				summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", session.ID, err))
+				continue
+			}
+			summary.Inserted++
+			continue
+		}
+
+		switch onConflict {
+		case "skip":
+			summary.Skipped++
+		case "replace":
+			if err := replaceSession(tx, session, messages); err != nil {
+				summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", session.ID, err))
+				continue
+			}
+			summary.Merged++
+		case "merge":
+			if _, err := mergeSessionMessages(tx, session, messages); err != nil {
+				summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", session.ID, err))
+				continue
+			}
+			summary.Merged++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	return summary, nil
+}
+
+// sessionExists reports whether a session with the given ID is already present.
+func sessionExists(tx *sql.Tx, id string) (bool, error) {
+	var exists int
+	err := tx.QueryRow("SELECT 1 FROM sessions WHERE id = ?", id).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// insertSession inserts a new session row along with all of its messages.
+// If session.Metadata is set (e.g. an agent binding reconstructed from an
+// AICS archive), it's persisted into session_metadata so it survives the
+// import instead of being dropped on the next read.
+func insertSession(tx *sql.Tx, session Session, messages []ParsedMessage) error {
+	messageCount := len(messages)
+	if _, err := tx.Exec(
+		"INSERT INTO sessions (id, title, created_at, message_count) VALUES (?, ?, ?, ?)",
+		session.ID, session.Title, session.CreatedAt, messageCount,
+	); err != nil {
+		return fmt.Errorf("failed to insert session: %w", err)
+	}
+
+	if session.Metadata != nil && *session.Metadata != "" {
+		if err := SetSessionMetadata(tx, session.ID, *session.Metadata); err != nil {
+			return err
+		}
+	}
+
+	for _, msg := range messages {
+		if err := insertMessage(tx, session.ID, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replaceSession deletes any existing session/messages and re-inserts them from scratch.
+func replaceSession(tx *sql.Tx, session Session, messages []ParsedMessage) error {
+	if _, err := tx.Exec("DELETE FROM messages WHERE session_id = ?", session.ID); err != nil {
+		return fmt.Errorf("failed to delete existing messages: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM sessions WHERE id = ?", session.ID); err != nil {
+		return fmt.Errorf("failed to delete existing session: %w", err)
+	}
+	return insertSession(tx, session, messages)
+}
+
+// mergeSessionMessages appends messages not already present in the existing
+// session, matched by ID and falling back to (role, timestamp, hash(parts))
+// when IDs collide across tools. It returns the number of messages added.
+func mergeSessionMessages(tx *sql.Tx, session Session, messages []ParsedMessage) (int, error) {
+	existingIDs := make(map[string]bool)
+	existingKeys := make(map[string]bool)
+
+	rows, err := tx.Query("SELECT id, role, created_at, parts FROM messages WHERE session_id = ?", session.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list existing messages: %w", err)
+	}
+	for rows.Next() {
+		var id, role string
+		var createdAt, parts *string
+		if err := rows.Scan(&id, &role, &createdAt, &parts); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan existing message: %w", err)
+		}
+		existingIDs[id] = true
+		existingKeys[messageDedupKey(role, createdAt, parts)] = true
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, err
+	}
+	rows.Close()
+
+	added := 0
+	for _, msg := range messages {
+		if existingIDs[msg.ID] {
+			continue
+		}
+
+		partsJSON, err := json.Marshal(msg.Parts)
+		if err != nil {
+			return added, fmt.Errorf("failed to marshal message parts: %w", err)
+		}
+		partsStr := string(partsJSON)
+
+		if existingKeys[messageDedupKey(msg.Role, msg.CreatedAt, &partsStr)] {
+			continue
+		}
+
+		if err := insertMessage(tx, session.ID, msg); err != nil {
+			return added, err
+		}
+		added++
+	}
+
+	if added > 0 {
+		if _, err := tx.Exec("UPDATE sessions SET message_count = message_count + ? WHERE id = ?", added, session.ID); err != nil {
+			return added, fmt.Errorf("failed to update message count: %w", err)
+		}
+	}
+
+	return added, nil
+}
+
+// insertMessage inserts a single message row under sessionID.
+func insertMessage(tx *sql.Tx, sessionID string, msg ParsedMessage) error {
+	partsJSON, err := json.Marshal(msg.Parts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message parts: %w", err)
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO messages (id, session_id, role, parts, model, provider, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		msg.ID, sessionID, msg.Role, string(partsJSON), msg.Model, msg.Provider, msg.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert message: %w", err)
+	}
+
+	return nil
+}
+
+// messageDedupKey builds the fallback identity used when merging messages
+// whose IDs collide across tools: (role, timestamp, hash(parts)).
+func messageDedupKey(role string, createdAt, parts *string) string {
+	ts := ""
+	if createdAt != nil {
+		ts = *createdAt
+	}
+	p := ""
+	if parts != nil {
+		p = *parts
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(p))
+	return fmt.Sprintf("%s|%s|%x", role, ts, h.Sum64())
+}
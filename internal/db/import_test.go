@@ -0,0 +1,43 @@
+package db
+
+import "testing"
+
+func TestMessageDedupKey(t *testing.T) {
+	ts := "2023-11-14T22:13:20Z"
+	parts := `["hello"]`
+	otherParts := `["goodbye"]`
+
+	key := messageDedupKey("user", &ts, &parts)
+
+	t.Run("stable for identical input", func(t *testing.T) {
+		if got := messageDedupKey("user", &ts, &parts); got != key {
+			t.Fatalf("messageDedupKey is not stable: got %q, want %q", got, key)
+		}
+	})
+
+	t.Run("differs on role", func(t *testing.T) {
+		if got := messageDedupKey("assistant", &ts, &parts); got == key {
+			t.Fatalf("messageDedupKey(%q) collided with messageDedupKey(%q)", "assistant", "user")
+		}
+	})
+
+	t.Run("differs on timestamp", func(t *testing.T) {
+		otherTs := "2023-11-14T22:13:21Z"
+		if got := messageDedupKey("user", &otherTs, &parts); got == key {
+			t.Fatalf("messageDedupKey collided across different timestamps")
+		}
+	})
+
+	t.Run("differs on parts content", func(t *testing.T) {
+		if got := messageDedupKey("user", &ts, &otherParts); got == key {
+			t.Fatalf("messageDedupKey collided across different parts")
+		}
+	})
+
+	t.Run("nil timestamp and parts treated as empty, not a crash", func(t *testing.T) {
+		empty := ""
+		if got, want := messageDedupKey("user", nil, nil), messageDedupKey("user", &empty, &empty); got != want {
+			t.Fatalf("messageDedupKey(nil, nil) = %q, want the empty-input key %q", got, want)
+		}
+	})
+}
@@ -30,9 +30,66 @@ type ParsedMessage struct {
 	ID        string   `json:"id"`
 	Role      string   `json:"role"`
 	Parts     []string `json:"parts"`
-	Model     *string  `json:"model"`
-	Provider  *string  `json:"provider"`
-	CreatedAt *string  `json:"created_at"`
+	// StructuredParts carries the original tool_call/tool_result payloads
+	// parallel to Parts (same index), when the underlying part had one.
+	// Entries with no structured data leave ToolCall/ToolResult nil.
+	StructuredParts []Part  `json:"structured_parts,omitempty"`
+	Model           *string `json:"model"`
+	Provider        *string `json:"provider"`
+	CreatedAt       *string `json:"created_at"`
+	// ParentID is the ID of the message this one forks from, set only when
+	// it isn't simply the previous message in the session's trunk order.
+	ParentID *string `json:"parent_id,omitempty"`
+	// BranchID identifies which branch this message belongs to. Empty means
+	// the message is on the session's original (trunk) history.
+	BranchID string `json:"branch_id,omitempty"`
+	// Segments is a richer, renderer-facing breakdown of the message content,
+	// parallel to Parts/StructuredParts (same index, when present): it keeps
+	// each part's Kind ("text", "tool_call", "tool_result", "code", "image")
+	// alongside any MIME type and extra structured data (e.g. an MCP tool's
+	// description or raw output) that doesn't fit in Text alone.
+	Segments []MessageSegment `json:"segments,omitempty"`
+}
+
+// MessageSegment is one entry of ParsedMessage.Segments.
+type MessageSegment struct {
+	Kind     string                 `json:"kind"`
+	Text     string                 `json:"text,omitempty"`
+	MimeType string                 `json:"mime_type,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+}
+
+// Branch represents a fork of a session's message history rooted at
+// ParentMessageID: every message tagged with this branch's ID diverges from
+// the session's other branches starting there.
+type Branch struct {
+	ID              string  `json:"id"`
+	SessionID       string  `json:"session_id"`
+	ParentMessageID string  `json:"parent_message_id"`
+	CreatedAt       *string `json:"created_at"`
+	Title           *string `json:"title"`
+}
+
+// Part is the structured counterpart of a single entry in ParsedMessage.Parts.
+type Part struct {
+	Text       string          `json:"text"`
+	ToolCall   *ToolCallData   `json:"tool_call,omitempty"`
+	ToolResult *ToolResultData `json:"tool_result,omitempty"`
+}
+
+// ToolCallData holds the original tool name, call ID, and JSON input for a
+// tool_call part, as stored in the SQLite `parts` JSON.
+type ToolCallData struct {
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+// ToolResultData holds the original JSON output and the ID of the tool_call
+// it answers, as stored in the SQLite `parts` JSON.
+type ToolResultData struct {
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+	Output     json.RawMessage `json:"output,omitempty"`
 }
 
 // ParsedCreatedAt returns the created_at timestamp as a time.Time
@@ -1,12 +1,33 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"strings"
 )
 
+// RawJSONOrString embeds s as raw JSON when it already holds a valid JSON
+// value (the normal case: tool call/result payloads are stored
+// pre-serialized), or quotes it as a JSON string otherwise, so
+// ToolCallData.Input/ToolResultData.Output (and any exporter built on top of
+// them, e.g. internal/markdown's JSON/JSONL renderers) never silently
+// double-encodes an already-JSON string into a JSON string literal.
+func RawJSONOrString(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	encoded, err := json.Marshal(s)
+	if err != nil {
+		return nil
+	}
+	return json.RawMessage(encoded)
+}
+
 // ListSessions retrieves sessions from the database with a limit
 func ListSessions(db *sql.DB, limit int) ([]Session, error) {
 	query := `
@@ -39,6 +60,39 @@ func ListSessions(db *sql.DB, limit int) ([]Session, error) {
 	return sessions, nil
 }
 
+// ListSessionsCtx is the context-aware counterpart of ListSessions, so a
+// long scan can be bounded by a --timeout deadline or cancelled on Ctrl-C.
+func ListSessionsCtx(ctx context.Context, db *sql.DB, limit int) ([]Session, error) {
+	query := `
+		SELECT id, title, created_at, message_count
+		FROM sessions
+		ORDER BY created_at DESC
+		LIMIT ?
+	`
+
+	rows, err := db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []Session
+	for rows.Next() {
+		var s Session
+		err := rows.Scan(&s.ID, &s.Title, &s.CreatedAt, &s.MessageCount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
 // FetchSession retrieves a specific session by ID
 func FetchSession(db *sql.DB, sessionID string) (*Session, error) {
 	query := `
@@ -59,21 +113,103 @@ func FetchSession(db *sql.DB, sessionID string) (*Session, error) {
 	return &s, nil
 }
 
-// ListMessages retrieves all messages for a session
-func ListMessages(db *sql.DB, sessionID string) ([]ParsedMessage, error) {
+// FetchSessionCtx is the context-aware counterpart of FetchSession.
+func FetchSessionCtx(ctx context.Context, db *sql.DB, sessionID string) (*Session, error) {
 	query := `
-		SELECT id, role, parts, model, provider, created_at
-		FROM messages
-		WHERE session_id = ?
-		ORDER BY created_at ASC
+		SELECT id, title, created_at, message_count
+		FROM sessions
+		WHERE id = ?
 	`
 
-	rows, err := db.Query(query, sessionID)
+	var s Session
+	err := db.QueryRowContext(ctx, query, sessionID).Scan(&s.ID, &s.Title, &s.CreatedAt, &s.MessageCount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session not found: %s", sessionID)
+		}
+		return nil, fmt.Errorf("failed to fetch session: %w", err)
+	}
+
+	return &s, nil
+}
+
+// InsertSession creates a new, empty session row. If session.Metadata is
+// set (e.g. an agent binding from NewSessionWithAgent), it's persisted into
+// session_metadata so later reads can restore it instead of always
+// recomputing a fresh, agent-less default (see CrushProvider.resolveMetadata).
+func InsertSession(conn *sql.DB, session Session) error {
+	if _, err := conn.Exec(
+		"INSERT INTO sessions (id, title, created_at, message_count) VALUES (?, ?, ?, ?)",
+		session.ID, session.Title, session.CreatedAt, 0,
+	); err != nil {
+		return fmt.Errorf("failed to insert session: %w", err)
+	}
+
+	if session.Metadata != nil && *session.Metadata != "" {
+		if err := SetSessionMetadata(conn, session.ID, *session.Metadata); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AppendMessage inserts a single message under sessionID and bumps the
+// session's message_count.
+func AppendMessage(conn *sql.DB, sessionID string, msg ParsedMessage) error {
+	partsJSON, err := json.Marshal(msg.Parts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message parts: %w", err)
+	}
+
+	if _, err := conn.Exec(
+		"INSERT INTO messages (id, session_id, role, parts, model, provider, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		msg.ID, sessionID, msg.Role, string(partsJSON), msg.Model, msg.Provider, msg.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to insert message: %w", err)
+	}
+
+	if _, err := conn.Exec("UPDATE sessions SET message_count = message_count + 1 WHERE id = ?", sessionID); err != nil {
+		return fmt.Errorf("failed to update message count: %w", err)
+	}
+
+	return nil
+}
+
+// listMessagesQuery is shared by ListMessages and ListMessagesCtx.
+const listMessagesQuery = `
+	SELECT id, role, parts, model, provider, created_at
+	FROM messages
+	WHERE session_id = ?
+	ORDER BY created_at ASC
+`
+
+// ListMessages retrieves all messages for a session
+func ListMessages(db *sql.DB, sessionID string) ([]ParsedMessage, error) {
+	rows, err := db.Query(listMessagesQuery, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query messages: %w", err)
 	}
 	defer rows.Close()
 
+	return scanMessages(rows)
+}
+
+// ListMessagesCtx is the context-aware counterpart of ListMessages.
+func ListMessagesCtx(ctx context.Context, db *sql.DB, sessionID string) ([]ParsedMessage, error) {
+	rows, err := db.QueryContext(ctx, listMessagesQuery, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMessages(rows)
+}
+
+// scanMessages parses the parts JSON blob of each row in rows into the
+// parallel Parts/StructuredParts/Segments representation, shared by
+// ListMessages and ListMessagesCtx.
+func scanMessages(rows *sql.Rows) ([]ParsedMessage, error) {
 	var messages []ParsedMessage
 	for rows.Next() {
 		var id, role string
@@ -103,6 +239,8 @@ func ListMessages(db *sql.DB, sessionID string) ([]ParsedMessage, error) {
 					case string:
 						if strings.TrimSpace(p) != "" {
 							parsed.Parts = append(parsed.Parts, p)
+							parsed.StructuredParts = append(parsed.StructuredParts, Part{Text: p})
+							parsed.Segments = append(parsed.Segments, MessageSegment{Kind: "text", Text: p})
 						}
 					case map[string]interface{}:
 						// Handle different message types
@@ -113,25 +251,81 @@ func ListMessages(db *sql.DB, sessionID string) ([]ParsedMessage, error) {
 								if data, ok := p["data"].(map[string]interface{}); ok {
 									if text, ok := data["text"].(string); ok && strings.TrimSpace(text) != "" {
 										parsed.Parts = append(parsed.Parts, text)
+										parsed.StructuredParts = append(parsed.StructuredParts, Part{Text: text})
+										parsed.Segments = append(parsed.Segments, MessageSegment{Kind: "text", Text: text})
 									}
 								}
 							case "tool_call":
-								// Handle tool calls - show what tool was called
+								// Handle tool calls - show what tool was called, and
+								// keep the original name/input JSON alongside it
 								if data, ok := p["data"].(map[string]interface{}); ok {
 									if name, ok := data["name"].(string); ok {
 										toolInfo := fmt.Sprintf("🔧 Tool call: %s", name)
-										if input, ok := data["input"].(string); ok && len(input) < 200 {
-											toolInfo += fmt.Sprintf("\nInput: %s", input)
+										var inputRaw json.RawMessage
+										if input, ok := data["input"].(string); ok {
+											if len(input) < 200 {
+												toolInfo += fmt.Sprintf("\nInput: %s", input)
+											}
+											inputRaw = RawJSONOrString(input)
+										}
+										var callID string
+										if id, ok := data["id"].(string); ok {
+											callID = id
 										}
 										parsed.Parts = append(parsed.Parts, toolInfo)
+										parsed.StructuredParts = append(parsed.StructuredParts, Part{
+											Text: toolInfo,
+											ToolCall: &ToolCallData{
+												ID:    callID,
+												Name:  name,
+												Input: inputRaw,
+											},
+										})
+										segData := map[string]interface{}{"name": name, "id": callID}
+										if input, ok := data["input"].(string); ok {
+											segData["input"] = input
+										}
+										if duration, ok := data["duration"]; ok {
+											segData["duration"] = duration
+										}
+										parsed.Segments = append(parsed.Segments, MessageSegment{
+											Kind: "tool_call",
+											Text: toolInfo,
+											Data: segData,
+										})
 									}
 								}
 							case "tool_result":
-								// Handle tool results - show the result
+								// Handle tool results - show the result, and keep the
+								// original output JSON alongside it
 								if data, ok := p["data"].(map[string]interface{}); ok {
 									if content, ok := data["content"].(string); ok && strings.TrimSpace(content) != "" {
 										result := fmt.Sprintf("📋 Tool result:\n%s", content)
+										var callID string
+										if id, ok := data["tool_call_id"].(string); ok {
+											callID = id
+										}
 										parsed.Parts = append(parsed.Parts, result)
+										parsed.StructuredParts = append(parsed.StructuredParts, Part{
+											Text: result,
+											ToolResult: &ToolResultData{
+												ToolCallID: callID,
+												Output:     RawJSONOrString(content),
+											},
+										})
+										segData := map[string]interface{}{"tool_call_id": callID, "content": content}
+										if isError, ok := data["is_error"].(bool); ok {
+											segData["is_error"] = isError
+										}
+										if errMsg, ok := data["error"].(string); ok && errMsg != "" {
+											segData["error"] = errMsg
+											segData["is_error"] = true
+										}
+										parsed.Segments = append(parsed.Segments, MessageSegment{
+											Kind: "tool_result",
+											Text: result,
+											Data: segData,
+										})
 									}
 								}
 							case "finish":
@@ -143,10 +337,14 @@ func ListMessages(db *sql.DB, sessionID string) ([]ParsedMessage, error) {
 							if textData, ok := p["text"]; ok {
 								if text, ok := textData.(string); ok && strings.TrimSpace(text) != "" {
 									parsed.Parts = append(parsed.Parts, text)
+									parsed.StructuredParts = append(parsed.StructuredParts, Part{Text: text})
+									parsed.Segments = append(parsed.Segments, MessageSegment{Kind: "text", Text: text})
 								}
 							} else if data, ok := p["data"].(map[string]interface{}); ok {
 								if text, ok := data["text"].(string); ok && strings.TrimSpace(text) != "" {
 									parsed.Parts = append(parsed.Parts, text)
+									parsed.StructuredParts = append(parsed.StructuredParts, Part{Text: text})
+									parsed.Segments = append(parsed.Segments, MessageSegment{Kind: "text", Text: text})
 								}
 							}
 						}
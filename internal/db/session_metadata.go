@@ -0,0 +1,65 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// sqlExecer is satisfied by both *sql.DB and *sql.Tx, letting
+// SetSessionMetadata/GetSessionMetadata be called from either a plain
+// connection (queries.go) or an in-flight transaction (import.go).
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// EnsureSessionMetadataSchema creates the session_metadata table used to
+// persist db.Session.Metadata (e.g. which agent created a session), if it
+// doesn't already exist. Unlike sessions/messages, which are owned and
+// created by Crush itself, this table belongs entirely to
+// crush-session-explorer (same rationale as EnsureBranchSchema), so it's
+// safe to create lazily against any Crush database the first time an
+// agent-bound session is created.
+func EnsureSessionMetadataSchema(conn sqlExecer) error {
+	if _, err := conn.Exec(`CREATE TABLE IF NOT EXISTS session_metadata (
+		session_id TEXT PRIMARY KEY,
+		metadata TEXT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create session_metadata table: %w", err)
+	}
+	return nil
+}
+
+// SetSessionMetadata persists metadata (the JSON object built by
+// providers.sessionMetadata) for sessionID, replacing whatever was stored
+// before.
+func SetSessionMetadata(conn sqlExecer, sessionID, metadata string) error {
+	if err := EnsureSessionMetadataSchema(conn); err != nil {
+		return err
+	}
+	if _, err := conn.Exec(
+		"INSERT INTO session_metadata (session_id, metadata) VALUES (?, ?) ON CONFLICT(session_id) DO UPDATE SET metadata = excluded.metadata",
+		sessionID, metadata,
+	); err != nil {
+		return fmt.Errorf("failed to store session metadata: %w", err)
+	}
+	return nil
+}
+
+// GetSessionMetadata returns the metadata persisted for sessionID, or "" if
+// none was ever stored (e.g. the session predates this table, or wasn't
+// created through a path that sets Session.Metadata).
+func GetSessionMetadata(conn sqlExecer, sessionID string) (string, error) {
+	if err := EnsureSessionMetadataSchema(conn); err != nil {
+		return "", err
+	}
+	var metadata string
+	err := conn.QueryRow("SELECT metadata FROM session_metadata WHERE session_id = ?", sessionID).Scan(&metadata)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch session metadata: %w", err)
+	}
+	return metadata, nil
+}
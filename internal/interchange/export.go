@@ -1,6 +1,7 @@
 package interchange
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -17,6 +18,13 @@ const toolVersion = "v1.0.1"
 
 // ExportToAICS exports sessions to the AICS (AI Coding Session) format
 func ExportToAICS(sessions []db.Session, messages map[string][]db.ParsedMessage, providerName string) (*Archive, error) {
+	return ExportToAICSCtx(context.Background(), sessions, messages, providerName, nil)
+}
+
+// ExportToAICSCtx is the context- and progress-aware variant of ExportToAICS.
+// progress, if non-nil, is called once per converted session with (done, total).
+// It returns ctx.Err() as soon as the context is cancelled, leaving archive nil.
+func ExportToAICSCtx(ctx context.Context, sessions []db.Session, messages map[string][]db.ParsedMessage, providerName string, progress func(done, total int)) (*Archive, error) {
 	archive := &Archive{
 		Version: FormatVersion,
 		Creator: Creator{
@@ -42,17 +50,33 @@ func ExportToAICS(sessions []db.Session, messages map[string][]db.ParsedMessage,
 	}
 
 	// Convert each session
-	for _, dbSession := range sessions {
+	for i, dbSession := range sessions {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		session, err := convertSession(dbSession, messages[dbSession.ID])
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert session %s: %w", dbSession.ID, err)
 		}
 		archive.Log.Sessions = append(archive.Log.Sessions, *session)
+
+		if progress != nil {
+			progress(i+1, len(sessions))
+		}
 	}
 
 	return archive, nil
 }
 
+// ConvertSessionForStream converts a single database session (and its
+// messages) to AICS format. It's the per-session counterpart of
+// ExportToAICS, used by NDJSON streaming export (--output -) to hand each
+// session to a StreamWriter one at a time instead of building a whole Archive.
+func ConvertSessionForStream(dbSession db.Session, messages []db.ParsedMessage) (*Session, error) {
+	return convertSession(dbSession, messages)
+}
+
 // convertSession converts a database session to AICS format
 func convertSession(dbSession db.Session, dbMessages []db.ParsedMessage) (*Session, error) {
 	session := &Session{
@@ -78,6 +102,17 @@ func convertSession(dbSession db.Session, dbMessages []db.ParsedMessage) (*Sessi
 		session.Metadata["message_count"] = *dbSession.MessageCount
 	}
 
+	// Thread the agent binding (if any) through so re-importing this archive
+	// restores which persona produced the session.
+	if dbSession.Metadata != nil && *dbSession.Metadata != "" {
+		var meta map[string]interface{}
+		if err := json.Unmarshal([]byte(*dbSession.Metadata), &meta); err == nil {
+			if agent, ok := meta["agent"].(string); ok && agent != "" {
+				session.Metadata["agent"] = agent
+			}
+		}
+	}
+
 	// Convert messages
 	for _, dbMsg := range dbMessages {
 		msg, err := convertMessage(dbMsg)
@@ -92,6 +127,22 @@ func convertSession(dbSession db.Session, dbMessages []db.ParsedMessage) (*Sessi
 		}
 	}
 
+	// Derive a Branches entry for every distinct branch referenced by the
+	// messages themselves. This loses a branch's Title/CreatedAt (those only
+	// live in the branches table, which callers here don't fetch), but keeps
+	// the fork topology round-trippable through ParentID/BranchID alone.
+	seenBranches := make(map[string]bool)
+	for _, msg := range session.Messages {
+		if msg.BranchID == "" || seenBranches[msg.BranchID] {
+			continue
+		}
+		seenBranches[msg.BranchID] = true
+		session.Branches = append(session.Branches, Branch{
+			ID:              msg.BranchID,
+			ParentMessageID: msg.ParentID,
+		})
+	}
+
 	return session, nil
 }
 
@@ -117,28 +168,109 @@ func convertMessage(dbMsg db.ParsedMessage) (*Message, error) {
 		msg.Timestamp = parseTimestamp(*dbMsg.CreatedAt)
 	}
 
-	// Convert message parts to content
-	for _, part := range dbMsg.Parts {
+	// Convert message parts to content. When the database preserved the
+	// original structured payload (StructuredParts), use it directly instead
+	// of re-detecting tool calls from decorated text.
+	hasStructured := len(dbMsg.StructuredParts) == len(dbMsg.Parts)
+	hasSegments := len(dbMsg.Segments) == len(dbMsg.Parts)
+	for i, part := range dbMsg.Parts {
+		var structured *db.Part
+		if hasStructured {
+			structured = &dbMsg.StructuredParts[i]
+		}
+
 		content := Content{
 			Type: "text",
 			Text: part,
 		}
 
-		// Detect tool calls and results based on content patterns
-		if len(part) > 0 {
-			if strings.HasPrefix(part, "ðŸ”§") {
-				content.Type = "tool_call"
-			} else if strings.HasPrefix(part, "ðŸ“‹") {
-				content.Type = "tool_result"
+		switch {
+		case structured != nil && structured.ToolCall != nil:
+			content.Type = "tool_call"
+			content.ToolName = structured.ToolCall.Name
+			content.ToolInput = json.RawMessage(structured.ToolCall.Input)
+			content.ToolCallID = structured.ToolCall.ID
+		case structured != nil && structured.ToolResult != nil:
+			content.Type = "tool_result"
+			content.ToolOutput = json.RawMessage(structured.ToolResult.Output)
+			content.ToolCallID = structured.ToolResult.ToolCallID
+		case strings.HasPrefix(part, "ðŸ”§"):
+			// No structured payload available (e.g. v1.0.1 database rows) -
+			// fall back to the legacy emoji-prefix heuristic.
+			content.Type = "tool_call"
+		case strings.HasPrefix(part, "ðŸ“‹"):
+			content.Type = "tool_result"
+		}
+
+		if hasSegments {
+			segment := dbMsg.Segments[i]
+			if segment.Kind != "" {
+				content.Type = segment.Kind
+			}
+			content.MimeType = segment.MimeType
+			if len(segment.Data) > 0 {
+				content.Data = Metadata(segment.Data)
 			}
 		}
 
 		msg.Content = append(msg.Content, content)
 	}
 
+	if hasSegments {
+		msg.MCP = buildMCPInfo(dbMsg.Segments)
+	}
+
+	if dbMsg.ParentID != nil {
+		msg.ParentID = *dbMsg.ParentID
+	}
+	msg.BranchID = dbMsg.BranchID
+
 	return msg, nil
 }
 
+// buildMCPInfo derives a message's MCP tool-call summary from its segments,
+// pairing each tool_call with the tool_result (if any) that answers it by
+// matching "id"/"tool_call_id", so AICS archives carry the same tool
+// invocation info Model Context Protocol clients would expose. Returns nil
+// when the message has no tool_call segment with a name.
+func buildMCPInfo(segments []db.MessageSegment) *MCPInfo {
+	outputs := make(map[string]interface{})
+	for _, seg := range segments {
+		if seg.Kind != "tool_result" {
+			continue
+		}
+		if id, ok := seg.Data["tool_call_id"].(string); ok && id != "" {
+			outputs[id] = seg.Data["content"]
+		}
+	}
+
+	var tools []MCPTool
+	for _, seg := range segments {
+		if seg.Kind != "tool_call" {
+			continue
+		}
+		name, _ := seg.Data["name"].(string)
+		if name == "" {
+			continue
+		}
+		tool := MCPTool{Name: name}
+		if input, ok := seg.Data["input"].(map[string]interface{}); ok {
+			tool.Input = input
+		}
+		if id, ok := seg.Data["id"].(string); ok {
+			if output, found := outputs[id]; found {
+				tool.Output = output
+			}
+		}
+		tools = append(tools, tool)
+	}
+
+	if len(tools) == 0 {
+		return nil
+	}
+	return &MCPInfo{Tools: tools}
+}
+
 // parseTimestamp attempts to parse various timestamp formats
 func parseTimestamp(ts string) *time.Time {
 	if ts == "" {
@@ -225,70 +357,40 @@ func GetClientID() (string, error) {
 	return clientID, nil
 }
 
-// ExportSessionToFile exports a single session to a file in a date-based folder structure
+// ExportSessionToFile exports a single session to a file in a date-based
+// folder structure. It is a thin wrapper around FSSink for callers that want
+// a plain filesystem path back instead of wiring up an ArchiveSink themselves.
 func ExportSessionToFile(session *Session, baseDir string, providerName string) (string, error) {
-	if session.StartedAt == nil {
-		return "", fmt.Errorf("session has no start time")
-	}
-
-	// Create folder structure: baseDir/YYYY/MM/DD/
-	year := session.StartedAt.Format("2006")
-	month := session.StartedAt.Format("01")
-	day := session.StartedAt.Format("02")
-
-	sessionDir := filepath.Join(baseDir, year, month, day)
-	if err := os.MkdirAll(sessionDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create session directory: %w", err)
-	}
-
-	// Create a single-session archive
-	archive := &Archive{
-		Version: FormatVersion,
-		Creator: Creator{
-			Name:    "crush-session-explorer",
-			Version: toolVersion,
-			Comment: "Exported from Crush database",
-		},
-		Browser: &Browser{
-			Name:    providerName,
-			Comment: "Original AI coding tool",
-		},
-		Log: Log{
-			Version: FormatVersion,
-			Creator: Creator{
-				Name:    "crush-session-explorer",
-				Version: toolVersion,
-			},
-			Browser: &Browser{
-				Name: providerName,
-			},
-			Sessions: []Session{*session},
-		},
-	}
-
-	// Convert to JSON
-	jsonData, err := archive.ToJSON()
+	key, err := SessionKey(session)
 	if err != nil {
-		return "", fmt.Errorf("failed to convert to JSON: %w", err)
+		return "", err
 	}
 
-	// Create filename based on session ID
-	filename := fmt.Sprintf("%s.aics.json", session.ID)
-	filePath := filepath.Join(sessionDir, filename)
-
-	// Write file
-	if err := os.WriteFile(filePath, jsonData, 0644); err != nil {
-		return "", fmt.Errorf("failed to write file: %w", err)
+	sink := NewFSSink(baseDir, providerName)
+	if err := sink.WriteSession(context.Background(), session, key); err != nil {
+		return "", err
 	}
 
-	return filePath, nil
+	return filepath.Join(baseDir, filepath.FromSlash(key)), nil
 }
 
 // ExportSessionsIndividually exports each session to its own file in a date-based folder structure
 func ExportSessionsIndividually(sessions []db.Session, messages map[string][]db.ParsedMessage, baseDir string, providerName string, clientID string) ([]string, error) {
+	return ExportSessionsIndividuallyCtx(context.Background(), sessions, messages, baseDir, providerName, clientID, nil)
+}
+
+// ExportSessionsIndividuallyCtx is the context- and progress-aware variant of
+// ExportSessionsIndividually. progress, if non-nil, is called once per
+// exported session with (done, total). On cancellation it returns ctx.Err()
+// along with the files already written, so callers can report partial progress.
+func ExportSessionsIndividuallyCtx(ctx context.Context, sessions []db.Session, messages map[string][]db.ParsedMessage, baseDir string, providerName string, clientID string, progress func(done, total int)) ([]string, error) {
 	var exportedFiles []string
 
-	for _, dbSession := range sessions {
+	for i, dbSession := range sessions {
+		if err := ctx.Err(); err != nil {
+			return exportedFiles, err
+		}
+
 		// Convert session
 		session, err := convertSession(dbSession, messages[dbSession.ID])
 		if err != nil {
@@ -310,6 +412,10 @@ func ExportSessionsIndividually(sessions []db.Session, messages map[string][]db.
 		}
 
 		exportedFiles = append(exportedFiles, filePath)
+
+		if progress != nil {
+			progress(i+1, len(sessions))
+		}
 	}
 
 	return exportedFiles, nil
@@ -1,6 +1,7 @@
 package interchange
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -57,6 +58,15 @@ type Session struct {
 	GitRefs   *GitRefs   `json:"gitRefs,omitempty"`   // Git references mentioned during session
 	Metadata  Metadata   `json:"metadata,omitempty"`  // Additional session metadata
 	Comment   string     `json:"comment,omitempty"`   // Additional information
+	Branches  []Branch   `json:"branches,omitempty"`  // Every branch forked from this session's trunk
+}
+
+// Branch describes a fork of a session's message history, recorded so
+// alternate conversation paths survive import/export alongside the trunk.
+type Branch struct {
+	ID              string `json:"id"`
+	ParentMessageID string `json:"parentMessageId"`
+	Title           string `json:"title,omitempty"`
 }
 
 // Message represents a single message in a session
@@ -70,16 +80,22 @@ type Message struct {
 	MCP       *MCPInfo   `json:"mcp,omitempty"`       // Model Context Protocol information
 	Metadata  Metadata   `json:"metadata,omitempty"`  // Additional message metadata
 	Comment   string     `json:"comment,omitempty"`   // Additional information
+	ParentID  string     `json:"parentId,omitempty"`  // ID of the message this one forks from, if not simply the previous one
+	BranchID  string     `json:"branchId,omitempty"`  // Which branch this message belongs to; empty means the trunk
 }
 
 // Content represents a content part within a message
 type Content struct {
-	Type     string   `json:"type"`               // Content type: "text", "tool_call", "tool_result", "code", "image"
-	Text     string   `json:"text,omitempty"`     // Text content
-	Data     Metadata `json:"data,omitempty"`     // Structured data for tool calls, results, etc.
-	MimeType string   `json:"mimeType,omitempty"` // MIME type for binary/encoded content
-	Encoding string   `json:"encoding,omitempty"` // Encoding for binary content (e.g., "base64")
-	Comment  string   `json:"comment,omitempty"`  // Additional information
+	Type       string          `json:"type"`                 // Content type: "text", "tool_call", "tool_result", "code", "image"
+	Text       string          `json:"text,omitempty"`       // Text content
+	Data       Metadata        `json:"data,omitempty"`       // Structured data for tool calls, results, etc.
+	ToolName   string          `json:"toolName,omitempty"`   // Name of the invoked tool, for type=="tool_call"
+	ToolInput  json.RawMessage `json:"toolInput,omitempty"`  // Original JSON input, for type=="tool_call"
+	ToolOutput json.RawMessage `json:"toolOutput,omitempty"` // Original JSON output, for type=="tool_result"
+	ToolCallID string          `json:"toolCallId,omitempty"` // Correlates a tool_result back to its tool_call
+	MimeType   string          `json:"mimeType,omitempty"`   // MIME type for binary/encoded content
+	Encoding   string          `json:"encoding,omitempty"`   // Encoding for binary content (e.g., "base64")
+	Comment    string          `json:"comment,omitempty"`    // Additional information
 }
 
 // Metadata represents flexible key-value metadata
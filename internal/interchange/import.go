@@ -1,12 +1,17 @@
 package interchange
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"crush-session-explorer/internal/db"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 // ImportFromAICS imports sessions from AICS format to internal database format
@@ -24,16 +29,48 @@ func ImportFromAICS(data []byte) (*Archive, error) {
 	return &archive, nil
 }
 
-// ImportFromFile imports sessions from an AICS file
+// ImportFromFile imports sessions from an AICS file. Gzip- and
+// zstd-compressed files (as produced by --compress=gzip|zstd) are sniffed by
+// their magic bytes and transparently decompressed.
 func ImportFromFile(filePath string) (*Archive, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	data, err = decompressIfNeeded(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress file: %w", err)
+	}
+
 	return ImportFromAICS(data)
 }
 
+// decompressIfNeeded sniffs the gzip/zstd magic bytes and decompresses data
+// if it recognizes either; otherwise it returns data unchanged.
+func decompressIfNeeded(data []byte) ([]byte, error) {
+	switch {
+	case len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b:
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		defer gz.Close()
+		return io.ReadAll(gz)
+
+	case len(data) >= 4 && data[0] == 0x28 && data[1] == 0xb5 && data[2] == 0x2f && data[3] == 0xfd:
+		zr, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open zstd stream: %w", err)
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+
+	default:
+		return data, nil
+	}
+}
+
 // ConvertToDBFormat converts AICS sessions to database format
 func (a *Archive) ConvertToDBFormat() ([]db.Session, map[string][]db.ParsedMessage, error) {
 	sessions := make([]db.Session, 0, len(a.Log.Sessions))
@@ -73,6 +110,15 @@ func convertAICSSession(aicsSession Session) (*db.Session, []db.ParsedMessage, e
 	messageCount := len(aicsSession.Messages)
 	dbSession.MessageCount = &messageCount
 
+	// Restore the agent binding, if the archive carries one.
+	if agent, ok := aicsSession.Metadata["agent"].(string); ok && agent != "" {
+		encoded, err := json.Marshal(map[string]string{"agent": agent})
+		if err == nil {
+			s := string(encoded)
+			dbSession.Metadata = &s
+		}
+	}
+
 	// Convert messages
 	dbMessages := make([]db.ParsedMessage, 0, len(aicsSession.Messages))
 	for _, aicsMsg := range aicsSession.Messages {
@@ -107,30 +153,72 @@ func convertAICSMessage(aicsMsg Message) (*db.ParsedMessage, error) {
 		dbMsg.CreatedAt = &createdAtStr
 	}
 
-	// Convert content to parts
+	// Convert content to parts, preserving the structured tool_call/tool_result
+	// payload alongside the flattened text. Archives written before ToolName
+	// existed (v1.0.1) carry no structured data, so we fall back to the old
+	// emoji-prefix heuristic purely for the flattened text representation.
 	dbMsg.Parts = make([]string, 0, len(aicsMsg.Content))
+	dbMsg.StructuredParts = make([]db.Part, 0, len(aicsMsg.Content))
+	dbMsg.Segments = make([]db.MessageSegment, 0, len(aicsMsg.Content))
 	for _, content := range aicsMsg.Content {
-		if content.Text != "" {
-			// Add emoji prefix based on content type
-			text := content.Text
-			switch content.Type {
-			case "tool_call":
-				if !strings.HasPrefix(text, "ðŸ”§") {
-					text = "ðŸ”§ " + text
+		text := content.Text
+		part := db.Part{Text: text}
+		segmentData := map[string]interface{}(content.Data)
+
+		switch content.Type {
+		case "tool_call":
+			if content.ToolName != "" || len(content.ToolInput) > 0 || content.ToolCallID != "" {
+				part.ToolCall = &db.ToolCallData{
+					ID:    content.ToolCallID,
+					Name:  content.ToolName,
+					Input: content.ToolInput,
+				}
+				if segmentData == nil {
+					segmentData = map[string]interface{}{"name": content.ToolName, "id": content.ToolCallID}
+				}
+			} else if text != "" && !strings.HasPrefix(text, "ðŸ”§") {
+				text = "ðŸ”§ " + text
+			}
+		case "tool_result":
+			if content.ToolCallID != "" || len(content.ToolOutput) > 0 {
+				part.ToolResult = &db.ToolResultData{
+					ToolCallID: content.ToolCallID,
+					Output:     content.ToolOutput,
 				}
-			case "tool_result":
-				if !strings.HasPrefix(text, "ðŸ“‹") {
-					text = "ðŸ“‹ " + text
+				if segmentData == nil {
+					segmentData = map[string]interface{}{"tool_call_id": content.ToolCallID}
 				}
+			} else if text != "" && !strings.HasPrefix(text, "ðŸ“‹") {
+				text = "ðŸ“‹ " + text
 			}
-			dbMsg.Parts = append(dbMsg.Parts, text)
 		}
+
+		if text == "" {
+			continue
+		}
+		part.Text = text
+		dbMsg.Parts = append(dbMsg.Parts, text)
+		dbMsg.StructuredParts = append(dbMsg.StructuredParts, part)
+		dbMsg.Segments = append(dbMsg.Segments, db.MessageSegment{
+			Kind:     content.Type,
+			Text:     text,
+			MimeType: content.MimeType,
+			Data:     segmentData,
+		})
 	}
 
+	if aicsMsg.ParentID != "" {
+		parentID := aicsMsg.ParentID
+		dbMsg.ParentID = &parentID
+	}
+	dbMsg.BranchID = aicsMsg.BranchID
+
 	return dbMsg, nil
 }
 
-// ValidateArchive performs basic validation on an AICS archive
+// ValidateArchive performs basic validation on an AICS archive. Content
+// entries may carry either the richer ToolName/ToolInput/ToolOutput fields
+// or just flattened text (v1.0.1 archives) - both shapes are accepted here.
 func ValidateArchive(archive *Archive) error {
 	if archive.Version == "" {
 		return fmt.Errorf("missing version field")
@@ -148,29 +236,47 @@ func ValidateArchive(archive *Archive) error {
 		return fmt.Errorf("archive contains no sessions")
 	}
 
-	// Validate each session
 	for i, session := range archive.Log.Sessions {
-		if session.ID == "" {
-			return fmt.Errorf("session %d: missing ID", i)
+		if err := validateSession(session, i); err != nil {
+			return err
 		}
+	}
 
-		if len(session.Messages) == 0 {
-			return fmt.Errorf("session %s: no messages", session.ID)
-		}
+	return nil
+}
 
-		// Validate each message
-		for j, msg := range session.Messages {
-			if msg.ID == "" {
-				return fmt.Errorf("session %s, message %d: missing ID", session.ID, j)
-			}
-			if msg.Role == "" {
-				return fmt.Errorf("session %s, message %s: missing role", session.ID, msg.ID)
-			}
-			if len(msg.Content) == 0 {
-				return fmt.Errorf("session %s, message %s: no content", session.ID, msg.ID)
-			}
+// validateSession validates a single AICS session, shared by ValidateArchive
+// (looping over an in-memory archive) and ValidateStream (validating each
+// session as it arrives over NDJSON). index is only used for sessions
+// missing an ID, to identify which one failed.
+func validateSession(session Session, index int) error {
+	if session.ID == "" {
+		return fmt.Errorf("session %d: missing ID", index)
+	}
+
+	if len(session.Messages) == 0 {
+		return fmt.Errorf("session %s: no messages", session.ID)
+	}
+
+	for j, msg := range session.Messages {
+		if msg.ID == "" {
+			return fmt.Errorf("session %s, message %d: missing ID", session.ID, j)
+		}
+		if msg.Role == "" {
+			return fmt.Errorf("session %s, message %s: missing role", session.ID, msg.ID)
+		}
+		if len(msg.Content) == 0 {
+			return fmt.Errorf("session %s, message %s: no content", session.ID, msg.ID)
 		}
 	}
 
 	return nil
 }
+
+// ConvertAICSSessionToDBFormat converts a single AICS session to database
+// format. It's the per-session counterpart of Archive.ConvertToDBFormat,
+// used by streaming import (--input -) to process sessions one at a time
+// instead of buffering a whole archive in memory.
+func ConvertAICSSessionToDBFormat(session Session) (*db.Session, []db.ParsedMessage, error) {
+	return convertAICSSession(session)
+}
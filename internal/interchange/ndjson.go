@@ -0,0 +1,210 @@
+package interchange
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// StreamHeader is the envelope recorded on the first line of an NDJSON AICS
+// stream (see NewStreamWriter), identifying which tool produced it.
+type StreamHeader struct {
+	Creator Creator  `json:"creator"`
+	Browser *Browser `json:"browser,omitempty"`
+}
+
+// ndjsonEnvelope is the on-the-wire shape of a stream's header line:
+// {"aics":"1.0","header":{...}}
+type ndjsonEnvelope struct {
+	AICS   string       `json:"aics"`
+	Header StreamHeader `json:"header"`
+}
+
+// ndjsonEndToken is the on-the-wire shape of a stream's closing line.
+type ndjsonEndToken struct {
+	AICSEnd string `json:"aics_end"`
+}
+
+// StreamWriter emits an AICS archive as newline-delimited JSON: a header
+// line, one JSON session object per line, then a closing token line. Unlike
+// WriteAICSStream (a single JSON document streamed incrementally), each line
+// here is independently greppable and parseable, making the format suitable
+// for piping straight into another crush-session-explorer invocation, e.g.
+// "export-aics --output - | import-aics --input - --format markdown".
+type StreamWriter struct {
+	w           io.Writer
+	enc         *json.Encoder
+	wroteHeader bool
+	closed      bool
+}
+
+// NewStreamWriter creates a StreamWriter over w. The header line is emitted
+// lazily, on the first WriteSession (or Close) call.
+func NewStreamWriter(w io.Writer) *StreamWriter {
+	return &StreamWriter{w: w, enc: json.NewEncoder(w)}
+}
+
+// WriteSession writes s as the next line of the stream, emitting the header
+// line first if this is the first call.
+func (sw *StreamWriter) WriteSession(s Session) error {
+	if err := sw.writeHeaderOnce(); err != nil {
+		return err
+	}
+	if err := sw.enc.Encode(s); err != nil {
+		return fmt.Errorf("failed to write session %s: %w", s.ID, err)
+	}
+	return nil
+}
+
+// Close writes the stream's closing token line.
+func (sw *StreamWriter) Close() error {
+	if sw.closed {
+		return nil
+	}
+	sw.closed = true
+
+	if err := sw.writeHeaderOnce(); err != nil {
+		return err
+	}
+	if err := sw.enc.Encode(ndjsonEndToken{AICSEnd: FormatVersion}); err != nil {
+		return fmt.Errorf("failed to write stream closing token: %w", err)
+	}
+	return nil
+}
+
+func (sw *StreamWriter) writeHeaderOnce() error {
+	if sw.wroteHeader {
+		return nil
+	}
+	sw.wroteHeader = true
+
+	envelope := ndjsonEnvelope{
+		AICS: FormatVersion,
+		Header: StreamHeader{
+			Creator: Creator{Name: "crush-session-explorer", Version: toolVersion},
+		},
+	}
+	if err := sw.enc.Encode(envelope); err != nil {
+		return fmt.Errorf("failed to write stream header: %w", err)
+	}
+	return nil
+}
+
+// StreamReader reads a StreamWriter-produced NDJSON stream back into
+// Sessions one at a time, so large archives can be consumed without
+// buffering the whole thing in memory.
+type StreamReader struct {
+	scanner    *bufio.Scanner
+	version    string
+	header     *StreamHeader
+	readHeader bool
+}
+
+// NewStreamReader creates a StreamReader over r.
+func NewStreamReader(r io.Reader) *StreamReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	return &StreamReader{scanner: scanner}
+}
+
+// Header returns the stream's envelope, reading the first line if this is
+// the first call made to the reader.
+func (sr *StreamReader) Header() (*StreamHeader, error) {
+	if err := sr.ensureHeader(); err != nil {
+		return nil, err
+	}
+	return sr.header, nil
+}
+
+func (sr *StreamReader) ensureHeader() error {
+	if sr.readHeader {
+		return nil
+	}
+
+	if !sr.scanner.Scan() {
+		if err := sr.scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read stream header: %w", err)
+		}
+		return fmt.Errorf("empty AICS stream: missing header line")
+	}
+
+	var envelope ndjsonEnvelope
+	if err := json.Unmarshal(sr.scanner.Bytes(), &envelope); err != nil {
+		return fmt.Errorf("failed to parse stream header: %w", err)
+	}
+	if envelope.AICS == "" {
+		return fmt.Errorf("invalid stream header: missing \"aics\" field")
+	}
+
+	sr.version = envelope.AICS
+	sr.header = &envelope.Header
+	sr.readHeader = true
+	return nil
+}
+
+// Next returns the next session in the stream, or io.EOF once the closing
+// token line is reached (or the underlying reader is exhausted).
+func (sr *StreamReader) Next() (*Session, error) {
+	if err := sr.ensureHeader(); err != nil {
+		return nil, err
+	}
+
+	if !sr.scanner.Scan() {
+		if err := sr.scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read stream: %w", err)
+		}
+		return nil, io.EOF
+	}
+
+	line := sr.scanner.Bytes()
+
+	var endToken ndjsonEndToken
+	if json.Unmarshal(line, &endToken) == nil && endToken.AICSEnd != "" {
+		return nil, io.EOF
+	}
+
+	var session Session
+	if err := json.Unmarshal(line, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse session: %w", err)
+	}
+	return &session, nil
+}
+
+// ValidateStream validates each session in an NDJSON AICS stream as it
+// arrives, without buffering the whole archive, applying the same rules as
+// ValidateArchive.
+func ValidateStream(r io.Reader) error {
+	sr := NewStreamReader(r)
+
+	header, err := sr.Header()
+	if err != nil {
+		return err
+	}
+	if sr.version != FormatVersion {
+		return fmt.Errorf("unsupported AICS version: %s (expected: %s)", sr.version, FormatVersion)
+	}
+	if header.Creator.Name == "" {
+		return fmt.Errorf("missing creator name")
+	}
+
+	count := 0
+	for {
+		session, err := sr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := validateSession(*session, count); err != nil {
+			return err
+		}
+		count++
+	}
+
+	if count == 0 {
+		return fmt.Errorf("archive contains no sessions")
+	}
+	return nil
+}
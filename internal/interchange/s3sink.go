@@ -0,0 +1,130 @@
+package interchange
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3SinkConfig holds the connection details for an S3-compatible endpoint
+// (AWS S3, MinIO, Backblaze B2, or GCS via its S3 interoperability API).
+type S3SinkConfig struct {
+	Endpoint     string
+	Bucket       string
+	Region       string
+	Prefix       string
+	AccessKey    string
+	SecretKey    string
+	UseSSL       bool
+	ProviderName string
+}
+
+// S3Sink uploads each session as its own object to an S3-compatible bucket,
+// so archives can be pushed straight to object storage without a local
+// staging directory.
+type S3Sink struct {
+	client       *minio.Client
+	bucket       string
+	prefix       string
+	providerName string
+}
+
+// NewS3Sink connects to an S3-compatible endpoint and returns an ArchiveSink
+// that uploads session objects under cfg.Prefix.
+func NewS3Sink(cfg S3SinkConfig) (*S3Sink, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	return &S3Sink{
+		client:       client,
+		bucket:       cfg.Bucket,
+		prefix:       strings.Trim(cfg.Prefix, "/"),
+		providerName: cfg.ProviderName,
+	}, nil
+}
+
+// WriteSession implements ArchiveSink.
+func (s *S3Sink) WriteSession(ctx context.Context, session *Session, key string) error {
+	jsonData, err := singleSessionArchive(session, s.providerName).ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to convert to JSON: %w", err)
+	}
+
+	objectKey := key
+	if s.prefix != "" {
+		objectKey = s.prefix + "/" + key
+	}
+
+	_, err = s.client.PutObject(ctx, s.bucket, objectKey, bytes.NewReader(jsonData), int64(len(jsonData)), minio.PutObjectOptions{
+		ContentType: "application/json",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload session %s to s3://%s/%s: %w", session.ID, s.bucket, objectKey, err)
+	}
+
+	return nil
+}
+
+// Close implements ArchiveSink. The underlying minio client has no
+// connection to tear down.
+func (s *S3Sink) Close() error { return nil }
+
+// PresignedURLSink uploads each session via an HTTP PUT to a caller-supplied
+// presigned URL, for callers who only hold a short-lived upload URL rather
+// than full bucket credentials.
+type PresignedURLSink struct {
+	urlFor       func(key string) (string, error)
+	providerName string
+	client       *http.Client
+}
+
+// NewPresignedURLSink wraps urlFor, which must resolve a session key to a
+// presigned PUT URL, as an ArchiveSink.
+func NewPresignedURLSink(providerName string, urlFor func(key string) (string, error)) *PresignedURLSink {
+	return &PresignedURLSink{urlFor: urlFor, providerName: providerName, client: http.DefaultClient}
+}
+
+// WriteSession implements ArchiveSink.
+func (s *PresignedURLSink) WriteSession(ctx context.Context, session *Session, key string) error {
+	jsonData, err := singleSessionArchive(session, s.providerName).ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to convert to JSON: %w", err)
+	}
+
+	url, err := s.urlFor(key)
+	if err != nil {
+		return fmt.Errorf("failed to resolve presigned URL for %s: %w", key, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to build upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to PUT session %s: %w", session.ID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("presigned PUT for session %s failed: %s", session.ID, resp.Status)
+	}
+
+	return nil
+}
+
+// Close implements ArchiveSink. PresignedURLSink holds no resources to release.
+func (s *PresignedURLSink) Close() error { return nil }
@@ -0,0 +1,136 @@
+package interchange
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"crush-session-explorer/internal/db"
+)
+
+// ArchiveSink writes individual session archives to a storage backend, keyed
+// by a YYYY/MM/DD/<uuid>.aics.json-style path. Implementations must be safe
+// to call repeatedly and to Close once the caller is done writing.
+type ArchiveSink interface {
+	// WriteSession writes a single-session archive under key.
+	WriteSession(ctx context.Context, session *Session, key string) error
+	// Close releases any resources held by the sink (connections, temp files, ...).
+	Close() error
+}
+
+// SessionKey builds the canonical YYYY/MM/DD/<id>.aics.json key for a
+// session. It always uses forward slashes regardless of OS, since it also
+// doubles as an object-storage key.
+func SessionKey(session *Session) (string, error) {
+	if session.StartedAt == nil {
+		return "", fmt.Errorf("session has no start time")
+	}
+
+	return fmt.Sprintf("%s/%s/%s/%s.aics.json",
+		session.StartedAt.Format("2006"),
+		session.StartedAt.Format("01"),
+		session.StartedAt.Format("02"),
+		session.ID), nil
+}
+
+// singleSessionArchive wraps one session in the minimal Archive envelope
+// ExportSessionToFile has always written.
+func singleSessionArchive(session *Session, providerName string) *Archive {
+	return &Archive{
+		Version: FormatVersion,
+		Creator: Creator{
+			Name:    "crush-session-explorer",
+			Version: toolVersion,
+			Comment: "Exported from Crush database",
+		},
+		Browser: &Browser{
+			Name:    providerName,
+			Comment: "Original AI coding tool",
+		},
+		Log: Log{
+			Version:  FormatVersion,
+			Creator:  Creator{Name: "crush-session-explorer", Version: toolVersion},
+			Browser:  &Browser{Name: providerName},
+			Sessions: []Session{*session},
+		},
+	}
+}
+
+// FSSink writes AICS session archives to the local filesystem under baseDir.
+// This is the original ExportSessionToFile behavior, now behind ArchiveSink
+// so callers can swap it for an object-storage sink without branching.
+type FSSink struct {
+	baseDir      string
+	providerName string
+}
+
+// NewFSSink creates a filesystem-backed ArchiveSink rooted at baseDir.
+func NewFSSink(baseDir, providerName string) *FSSink {
+	return &FSSink{baseDir: baseDir, providerName: providerName}
+}
+
+// WriteSession implements ArchiveSink.
+func (s *FSSink) WriteSession(ctx context.Context, session *Session, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	jsonData, err := singleSessionArchive(session, s.providerName).ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to convert to JSON: %w", err)
+	}
+
+	filePath := filepath.Join(s.baseDir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+	if err := os.WriteFile(filePath, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// Close implements ArchiveSink. FSSink holds no resources to release.
+func (s *FSSink) Close() error { return nil }
+
+// ExportSessionsToSinkCtx converts each session and writes it to sink under
+// its canonical SessionKey, mirroring ExportSessionsIndividuallyCtx but
+// against any ArchiveSink rather than hardcoding the filesystem. progress,
+// if non-nil, is called once per written session with (done, total).
+func ExportSessionsToSinkCtx(ctx context.Context, sink ArchiveSink, sessions []db.Session, messages map[string][]db.ParsedMessage, clientID string, progress func(done, total int)) ([]string, error) {
+	var keys []string
+
+	for i, dbSession := range sessions {
+		if err := ctx.Err(); err != nil {
+			return keys, err
+		}
+
+		session, err := convertSession(dbSession, messages[dbSession.ID])
+		if err != nil {
+			return keys, fmt.Errorf("failed to convert session %s: %w", dbSession.ID, err)
+		}
+
+		session.ID = GenerateSessionID()
+		if clientID != "" {
+			session.ClientID = clientID
+		}
+
+		key, err := SessionKey(session)
+		if err != nil {
+			return keys, fmt.Errorf("failed to derive key for session %s: %w", session.ID, err)
+		}
+
+		if err := sink.WriteSession(ctx, session, key); err != nil {
+			return keys, fmt.Errorf("failed to write session %s: %w", session.ID, err)
+		}
+
+		keys = append(keys, key)
+		if progress != nil {
+			progress(i+1, len(sessions))
+		}
+	}
+
+	return keys, nil
+}
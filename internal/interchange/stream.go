@@ -0,0 +1,133 @@
+package interchange
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"crush-session-explorer/internal/db"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression identifies an optional transparent compression layer for
+// streamed AICS output.
+type Compression string
+
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// Extension returns the filename suffix conventionally appended for this
+// compression (e.g. ".gz"), or "" for CompressionNone.
+func (c Compression) Extension() string {
+	switch c {
+	case CompressionGzip:
+		return ".gz"
+	case CompressionZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// StreamOptions controls WriteAICSStream's output.
+type StreamOptions struct {
+	ProviderName string
+	Compression  Compression
+}
+
+// WriteAICSStream emits an AICS archive to w without holding every session's
+// messages in memory at once.
+func WriteAICSStream(w io.Writer, sessions []db.Session, fetch func(id string) ([]db.ParsedMessage, error), opts StreamOptions) error {
+	return WriteAICSStreamCtx(context.Background(), w, sessions, fetch, opts, nil)
+}
+
+// WriteAICSStreamCtx is the context- and progress-aware variant of
+// WriteAICSStream: it writes the envelope fields up front, then streams each
+// session's JSON as fetch(id) resolves its messages one at a time, instead of
+// building the whole Archive in RAM the way ExportToAICS / ToJSON do.
+// progress, if non-nil, is called once per streamed session with (done, total).
+func WriteAICSStreamCtx(ctx context.Context, w io.Writer, sessions []db.Session, fetch func(id string) ([]db.ParsedMessage, error), opts StreamOptions, progress func(done, total int)) error {
+	dest, closeDest, err := wrapCompression(w, opts.Compression)
+	if err != nil {
+		return err
+	}
+	defer closeDest()
+
+	enc := json.NewEncoder(dest)
+
+	header := fmt.Sprintf(
+		`{"version":%s,"creator":{"name":"crush-session-explorer","version":%s},"browser":{"name":%s},"log":{"version":%s,"creator":{"name":"crush-session-explorer","version":%s},"browser":{"name":%s},"sessions":[`,
+		jsonString(FormatVersion), jsonString(toolVersion), jsonString(opts.ProviderName),
+		jsonString(FormatVersion), jsonString(toolVersion), jsonString(opts.ProviderName),
+	)
+	if _, err := io.WriteString(dest, header); err != nil {
+		return fmt.Errorf("failed to write archive header: %w", err)
+	}
+
+	for i, dbSession := range sessions {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		messages, err := fetch(dbSession.ID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch messages for session %s: %w", dbSession.ID, err)
+		}
+
+		session, err := convertSession(dbSession, messages)
+		if err != nil {
+			return fmt.Errorf("failed to convert session %s: %w", dbSession.ID, err)
+		}
+
+		if i > 0 {
+			if _, err := io.WriteString(dest, ","); err != nil {
+				return fmt.Errorf("failed to write session separator: %w", err)
+			}
+		}
+		if err := enc.Encode(session); err != nil {
+			return fmt.Errorf("failed to encode session %s: %w", dbSession.ID, err)
+		}
+
+		if progress != nil {
+			progress(i+1, len(sessions))
+		}
+	}
+
+	if _, err := io.WriteString(dest, "]}}"); err != nil {
+		return fmt.Errorf("failed to write archive footer: %w", err)
+	}
+
+	return nil
+}
+
+// jsonString renders s as a quoted JSON string literal.
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// wrapCompression layers gzip/zstd compression over w, returning the
+// (possibly wrapped) writer and a close function that flushes/closes it.
+func wrapCompression(w io.Writer, c Compression) (io.Writer, func() error, error) {
+	switch c {
+	case "", CompressionNone:
+		return w, func() error { return nil }, nil
+	case CompressionGzip:
+		gz := gzip.NewWriter(w)
+		return gz, gz.Close, nil
+	case CompressionZstd:
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create zstd writer: %w", err)
+		}
+		return zw, zw.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported compression: %s (supported: none, gzip, zstd)", c)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"html"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -27,67 +28,163 @@ func getRoleEmoji(role string) string {
 	}
 }
 
-// formatTimeOnly formats a timestamp to show only time (HH:MM:SS)
-func formatTimeOnly(ts *string) string {
+// parseFlexibleTime parses a timestamp that may be either a Unix epoch
+// (seconds, as stored by the Crush provider) or an RFC3339 string (as used
+// by AICS imports), the fallback ladder the HTML and JSON renderers in this
+// package share (GenerateFilename in renderer.go parses the epoch form only).
+// Returns nil if ts is empty or matches neither shape.
+func parseFlexibleTime(ts *string) *time.Time {
 	if ts == nil || *ts == "" {
-		return "Unknown"
+		return nil
 	}
 
-	// Try parsing as Unix timestamp
 	if timestamp, err := strconv.ParseInt(*ts, 10, 64); err == nil {
-		return time.Unix(timestamp, 0).Format("15:04:05")
+		t := time.Unix(timestamp, 0)
+		return &t
 	}
 
-	// Try parsing as ISO format
 	if t, err := time.Parse(time.RFC3339, *ts); err == nil {
-		return t.Local().Format("15:04:05")
+		return &t
 	}
 
-	// Return as-is if parsing fails
-	return *ts
+	return nil
 }
 
-// formatDateOnly formats a timestamp to show only date (YYYY-MM-DD)
-func formatDateOnly(ts *string) string {
-	if ts == nil || *ts == "" {
-		return ""
-	}
-
-	// Try parsing as Unix timestamp
-	if timestamp, err := strconv.ParseInt(*ts, 10, 64); err == nil {
-		return time.Unix(timestamp, 0).Format("2006-01-02")
+// formatTimeOnly formats a timestamp to show only time (HH:MM:SS) in loc.
+func formatTimeOnly(ts *string, loc *time.Location) string {
+	t := parseFlexibleTime(ts)
+	if t == nil {
+		if ts != nil && *ts != "" {
+			return *ts
+		}
+		return "Unknown"
 	}
+	return t.In(loc).Format("15:04:05")
+}
 
-	// Try parsing as ISO format
-	if t, err := time.Parse(time.RFC3339, *ts); err == nil {
-		return t.Local().Format("2006-01-02")
+// formatDateOnly formats a timestamp to show only date (YYYY-MM-DD) in loc.
+func formatDateOnly(ts *string, loc *time.Location) string {
+	t := parseFlexibleTime(ts)
+	if t == nil {
+		return ""
 	}
-
-	return ""
+	return t.In(loc).Format("2006-01-02")
 }
 
 // parseMessageTime parses a timestamp to time.Time
 func parseMessageTime(ts *string) *time.Time {
-	if ts == nil || *ts == "" {
-		return nil
-	}
+	return parseFlexibleTime(ts)
+}
 
-	// Try parsing as Unix timestamp
-	if timestamp, err := strconv.ParseInt(*ts, 10, 64); err == nil {
-		t := time.Unix(timestamp, 0)
-		return &t
+// renderMessageTime renders a message timestamp as a <time> element: the
+// visible text is the absolute time in opts' timezone, the title tooltip
+// spells out both that zone and UTC, and the datetime attribute carries the
+// UTC instant the embedded relative-time script (see generateHTMLFooter)
+// reads on load. Falls back to the raw string, or "Unknown", if ts doesn't
+// parse.
+func renderMessageTime(ts *string, opts RenderOptions) string {
+	t := parseFlexibleTime(ts)
+	if t == nil {
+		if ts != nil && *ts != "" {
+			return html.EscapeString(*ts)
+		}
+		return "Unknown"
 	}
 
-	// Try parsing as ISO format
-	if t, err := time.Parse(time.RFC3339, *ts); err == nil {
-		return &t
+	loc := opts.loc()
+	abs := t.In(loc).Format("15:04:05")
+	title := fmt.Sprintf("%s · %s", t.In(loc).Format("2006-01-02 15:04:05 MST"), t.UTC().Format("2006-01-02 15:04:05 UTC"))
+
+	relativeSpan := ""
+	if opts.Relative {
+		relativeSpan = ` <span class="relative-time"></span>`
 	}
 
-	return nil
+	return fmt.Sprintf(`<time datetime="%s" title="%s">%s</time>%s`,
+		t.UTC().Format(time.RFC3339), html.EscapeString(title), html.EscapeString(abs), relativeSpan)
+}
+
+// RenderOptions controls the locale/timezone a session is rendered in, so a
+// page exported for sharing can pin absolute timestamps to a fixed zone
+// (e.g. UTC) instead of whatever the exporting machine's local zone happens
+// to be. The zero value is not valid on its own - use DefaultRenderOptions.
+type RenderOptions struct {
+	// Timezone is the zone absolute timestamps are rendered in. Defaults to
+	// time.Local.
+	Timezone *time.Location
+
+	// Relative, when true, renders each message time as
+	// "HH:MM:SS · 3 minutes ago" (wording from the browser's
+	// Intl.RelativeTimeFormat in Locale), with the "ago" portion recomputed
+	// client-side on load so a page left open stays accurate.
+	Relative bool
+
+	// Locale is passed straight through to the embedded JS's
+	// Intl.RelativeTimeFormat for the relative portion; empty means "browser
+	// default".
+	Locale string
+}
+
+// DefaultRenderOptions is what RenderHTML/RenderHTMLWithBranches/
+// RenderHTMLWithNav use: local time, with relative "N ago" labels on.
+func DefaultRenderOptions() RenderOptions {
+	return RenderOptions{Timezone: time.Local, Relative: true}
+}
+
+func (o RenderOptions) loc() *time.Location {
+	if o.Timezone != nil {
+		return o.Timezone
+	}
+	return time.Local
 }
 
 // RenderHTML converts a session and messages to HTML format with collapsible panels and timeline
 func RenderHTML(session *db.Session, messages []db.ParsedMessage) string {
+	return RenderHTMLWithBranches(session, messages, nil)
+}
+
+// RenderHTMLWithBranches is the branch-aware variant of RenderHTML: messages
+// should already be restricted to whichever branch the caller wants
+// rendered (see db.ListMessagesOnBranch); branches, when non-empty, is
+// rendered as a collapsible tree listing every fork of the session.
+func RenderHTMLWithBranches(session *db.Session, messages []db.ParsedMessage, branches []db.Branch) string {
+	return renderHTMLDocument(session, messages, branches, NavLinks{}, DefaultRenderOptions())
+}
+
+// NavLinks points a per-session page at its neighbours in a multi-session
+// export (see RenderIndexHTML), so a reader can move through an archive
+// without going back to the index for every session. A zero-value NavLinks
+// renders no nav bar at all.
+type NavLinks struct {
+	IndexHref string
+
+	PrevHref  string
+	PrevLabel string
+
+	NextHref  string
+	NextLabel string
+}
+
+func (n NavLinks) isEmpty() bool {
+	return n.IndexHref == "" && n.PrevHref == "" && n.NextHref == ""
+}
+
+// RenderHTMLWithNav is the archive-aware variant of RenderHTMLWithBranches:
+// it additionally renders a prev/next/index nav bar in the page header,
+// linking to the neighbouring session files RenderIndexHTML produced.
+func RenderHTMLWithNav(session *db.Session, messages []db.ParsedMessage, branches []db.Branch, nav NavLinks) string {
+	return renderHTMLDocument(session, messages, branches, nav, DefaultRenderOptions())
+}
+
+// RenderHTMLWithOptions is the fully-parameterized entry point: it exposes
+// RenderOptions (timezone, relative labels, locale) to callers such as a
+// --timezone CLI flag that the thin RenderHTML/RenderHTMLWithBranches/
+// RenderHTMLWithNav wrappers don't need.
+func RenderHTMLWithOptions(session *db.Session, messages []db.ParsedMessage, branches []db.Branch, nav NavLinks, opts RenderOptions) string {
+	return renderHTMLDocument(session, messages, branches, nav, opts)
+}
+
+func renderHTMLDocument(session *db.Session, messages []db.ParsedMessage, branches []db.Branch, nav NavLinks, opts RenderOptions) string {
 	var result strings.Builder
 
 	// Generate title
@@ -99,9 +196,18 @@ func RenderHTML(session *db.Session, messages []db.ParsedMessage) string {
 	// Start HTML document
 	result.WriteString(generateHTMLHeader(title))
 
+	// Add the left-hand table-of-contents, then open the main column
+	result.WriteString(generateSidebar(messages, opts))
+	result.WriteString("<div class=\"main-content\">\n<div class=\"container\">\n")
+	result.WriteString(fmt.Sprintf("<div class=\"header\">\n<h1>%s</h1>\n</div>\n", html.EscapeString(title)))
+	result.WriteString(generateSessionNav(nav))
+
 	// Add session metadata
 	result.WriteString(generateSessionInfo(session))
 
+	// Add branch tree, if this session has any forks
+	result.WriteString(generateBranchTree(branches))
+
 	// Add conversation container
 	result.WriteString("<div class=\"conversation\">\n")
 
@@ -110,7 +216,7 @@ func RenderHTML(session *db.Session, messages []db.ParsedMessage) string {
 		// Check if we need a date separator
 		msgTime := parseMessageTime(msg.CreatedAt)
 		if msgTime != nil {
-			currentDate := msgTime.Format("2006-01-02")
+			currentDate := msgTime.In(opts.loc()).Format("2006-01-02")
 			if currentDate != lastDate {
 				if i > 0 { // Don't add separator before first message
 					result.WriteString(generateDateSeparator(currentDate))
@@ -118,18 +224,65 @@ func RenderHTML(session *db.Session, messages []db.ParsedMessage) string {
 				lastDate = currentDate
 			}
 		}
-		
-		result.WriteString(generateMessage(msg, i))
+
+		result.WriteString(generateMessage(msg, i, opts))
 	}
 
 	result.WriteString("</div>\n")
 
+	// Close the main column (container, main-content)
+	result.WriteString("</div>\n</div>\n")
+
 	// Close HTML document
-	result.WriteString(generateHTMLFooter())
+	result.WriteString(generateHTMLFooter(opts))
+
+	return result.String()
+}
+
+// generateSidebar renders the left-hand table-of-contents: one entry per
+// message, linking to its #msg-N anchor and tagged with data-role so the
+// toolbar's role checkboxes can filter it the same way they filter the
+// conversation itself.
+func generateSidebar(messages []db.ParsedMessage, opts RenderOptions) string {
+	var result strings.Builder
+
+	result.WriteString("<nav class=\"sidebar\" aria-label=\"Message index\">\n")
+	result.WriteString("<h3>Messages</h3>\n<ul class=\"toc\">\n")
+
+	for i, msg := range messages {
+		anchorName := fmt.Sprintf("msg-%d", i+1)
+		timeOnly := formatTimeOnly(msg.CreatedAt, opts.loc())
 
+		result.WriteString(fmt.Sprintf(`<li><a href="#%s" class="toc-entry" data-role="%s"><span>%s</span><span class="toc-time">%s</span><span class="toc-preview">%s</span></a></li>
+`, anchorName, html.EscapeString(strings.ToLower(msg.Role)), getRoleEmoji(msg.Role),
+			html.EscapeString(timeOnly), html.EscapeString(tocPreview(msg))))
+	}
+
+	result.WriteString("</ul>\n</nav>\n")
 	return result.String()
 }
 
+// tocPreview returns the first line of a message's first part, truncated to
+// a length that fits the sidebar's fixed width.
+func tocPreview(msg db.ParsedMessage) string {
+	if len(msg.Parts) == 0 {
+		return ""
+	}
+
+	first := msg.Parts[0]
+	if idx := strings.IndexAny(first, "\n\r"); idx >= 0 {
+		first = first[:idx]
+	}
+	first = strings.TrimSpace(first)
+
+	const maxRunes = 60
+	runes := []rune(first)
+	if len(runes) > maxRunes {
+		first = string(runes[:maxRunes]) + "…"
+	}
+	return first
+}
+
 // generateHTMLHeader creates the HTML header with embedded CSS and JavaScript
 func generateHTMLHeader(title string) string {
 	return fmt.Sprintf(`<!DOCTYPE html>
@@ -272,6 +425,10 @@ func generateHTMLHeader(title string) string {
             white-space: nowrap;
         }
 
+        .relative-time {
+            color: #999;
+        }
+
         .message-model {
             font-size: 0.75em;
             color: #888;
@@ -318,6 +475,268 @@ func generateHTMLHeader(title string) string {
             whitespace: pre;
         }
 
+        .message-part pre {
+            overflow-x: auto;
+            padding: 10px;
+            border-radius: 4px;
+            margin: 8px 0;
+        }
+
+        .message-part code {
+            font-family: 'Consolas', 'Monaco', 'Courier New', monospace;
+        }
+
+        /* Syntax highlighting for fenced code blocks (see internal/markdown/render.go) */
+        %s
+
+        .toolbar {
+            position: sticky;
+            top: 0;
+            z-index: 900;
+            display: flex;
+            align-items: center;
+            gap: 15px;
+            background: white;
+            padding: 12px 20px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            flex-wrap: wrap;
+        }
+
+        #search-box {
+            flex: 1;
+            min-width: 200px;
+            padding: 8px 12px;
+            border: 1px solid #ddd;
+            border-radius: 6px;
+            font-size: 0.95em;
+        }
+
+        .role-filters {
+            display: flex;
+            gap: 12px;
+            font-size: 0.9em;
+            color: #555;
+            white-space: nowrap;
+        }
+
+        .role-filters label {
+            display: flex;
+            align-items: center;
+            gap: 4px;
+            cursor: pointer;
+        }
+
+        .match-counter {
+            font-size: 0.85em;
+            color: #666;
+            white-space: nowrap;
+        }
+
+        .layout {
+            display: flex;
+            align-items: flex-start;
+            gap: 20px;
+            max-width: 100rem;
+            margin: 0 auto;
+            padding: 0 20px;
+        }
+
+        .sidebar {
+            width: 280px;
+            flex-shrink: 0;
+            position: sticky;
+            top: 70px;
+            max-height: calc(100vh - 90px);
+            overflow-y: auto;
+            background: white;
+            border-radius: 10px;
+            padding: 15px;
+            margin-top: 20px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+        }
+
+        .sidebar h3 {
+            color: #667eea;
+            margin-bottom: 10px;
+            font-size: 1em;
+        }
+
+        .toc {
+            list-style: none;
+        }
+
+        .toc-entry {
+            display: flex;
+            align-items: baseline;
+            gap: 6px;
+            padding: 6px 4px;
+            border-radius: 4px;
+            color: inherit;
+            text-decoration: none;
+            font-size: 0.85em;
+        }
+
+        .toc-entry:hover {
+            background: #f0f2ff;
+        }
+
+        .toc-time {
+            color: #888;
+            white-space: nowrap;
+        }
+
+        .toc-preview {
+            color: #444;
+            overflow: hidden;
+            text-overflow: ellipsis;
+            white-space: nowrap;
+        }
+
+        .main-content {
+            flex: 1;
+            min-width: 0;
+        }
+
+        mark {
+            background: #fff3a0;
+            color: inherit;
+            border-radius: 2px;
+        }
+
+        .tool-panel {
+            font-family: 'Consolas', 'Monaco', 'Courier New', monospace;
+            background: #f1f1f1;
+            border-left-color: #666;
+        }
+
+        .tool-panel summary {
+            cursor: pointer;
+            display: flex;
+            align-items: center;
+            gap: 10px;
+        }
+
+        .tool-name {
+            font-weight: bold;
+        }
+
+        .tool-status {
+            font-size: 0.75em;
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
+            padding: 2px 8px;
+            border-radius: 10px;
+            text-transform: uppercase;
+            letter-spacing: 0.5px;
+        }
+
+        .tool-status-ok {
+            background: #d4edda;
+            color: #155724;
+        }
+
+        .tool-status-error {
+            background: #f8d7da;
+            color: #721c24;
+        }
+
+        .tool-status-pending {
+            background: #e2e3e5;
+            color: #383d41;
+        }
+
+        .tool-panel-error {
+            border-left-color: #dc3545;
+        }
+
+        .tool-args-label, .tool-result-label {
+            margin-top: 10px;
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
+            font-size: 0.75em;
+            font-weight: bold;
+            color: #666;
+            text-transform: uppercase;
+            letter-spacing: 0.5px;
+        }
+
+        .tool-args {
+            overflow-x: auto;
+            white-space: pre-wrap;
+            word-wrap: break-word;
+        }
+
+        .tool-duration {
+            margin-top: 6px;
+            font-size: 0.8em;
+            color: #888;
+        }
+
+        .tool-error {
+            margin-top: 6px;
+            color: #721c24;
+        }
+
+        .tool-result-body {
+            position: relative;
+        }
+
+        .tool-result-body.truncated {
+            max-height: 280px;
+            overflow: hidden;
+        }
+
+        .tool-result-body.truncated::after {
+            content: '';
+            position: absolute;
+            bottom: 0;
+            left: 0;
+            right: 0;
+            height: 40px;
+            background: linear-gradient(transparent, #f1f1f1);
+        }
+
+        .tool-result-toggle {
+            margin-top: 6px;
+            background: none;
+            border: 1px solid #ccc;
+            border-radius: 4px;
+            padding: 4px 10px;
+            font-size: 0.8em;
+            color: #555;
+            cursor: pointer;
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
+        }
+
+        .tool-result-toggle:hover {
+            background: #e9e9e9;
+        }
+
+        .session-nav {
+            display: flex;
+            justify-content: space-between;
+            align-items: center;
+            gap: 10px;
+            background: white;
+            border-radius: 10px;
+            padding: 12px 20px;
+            margin-bottom: 30px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+        }
+
+        .session-nav a {
+            color: #667eea;
+            text-decoration: none;
+            font-weight: 600;
+            font-size: 0.9em;
+        }
+
+        .session-nav a:hover {
+            text-decoration: underline;
+        }
+
+        .session-nav-index {
+            margin: 0 auto;
+        }
+
         .date-separator {
             background: #f8f9fa;
             border-top: 1px solid #e9ecef;
@@ -372,29 +791,46 @@ func generateHTMLHeader(title string) string {
             .container {
                 padding: 10px;
             }
-            
+
             .header h1 {
                 font-size: 2em;
             }
-            
+
             .message {
                 grid-template-columns: 1fr;
             }
-            
+
             .message-sidebar {
                 border-right: none;
                 border-bottom: 1px solid #f0f0f0;
                 flex-wrap: wrap;
             }
+
+            .layout {
+                flex-direction: column;
+            }
+
+            .sidebar {
+                width: auto;
+                position: static;
+                max-height: none;
+            }
         }
     </style>
 </head>
 <body>
-    <div class="container">
-        <div class="header">
-            <h1>%s</h1>
+    <div class="toolbar">
+        <input type="text" id="search-box" placeholder="Search messages… (press / to focus)" aria-label="Search messages">
+        <div class="role-filters">
+            <label><input type="checkbox" class="role-filter" value="user" checked> User</label>
+            <label><input type="checkbox" class="role-filter" value="assistant" checked> Assistant</label>
+            <label><input type="checkbox" class="role-filter" value="tool" checked> Tool</label>
+            <label><input type="checkbox" class="role-filter" value="system" checked> System</label>
         </div>
-`, html.EscapeString(title), html.EscapeString(title))
+        <div class="match-counter" id="match-counter"></div>
+    </div>
+    <div class="layout">
+`, html.EscapeString(title), chromaCSS)
 }
 
 // generateSessionInfo creates the session information section
@@ -453,35 +889,178 @@ func generateSessionInfo(session *db.Session) string {
 	return result.String()
 }
 
-// generateDateSeparator creates a date separator line
-func generateDateSeparator(date string) string {
-	// Parse date to format it nicely
-	if t, err := time.Parse("2006-01-02", date); err == nil {
-		formattedDate := t.Format("Monday, January 2, 2006")
-		return fmt.Sprintf(`
-    <div class="date-separator">
-        %s
-    </div>
-`, formattedDate)
+// generateToolPanel renders a tool_call and, when one was found in the same
+// message, the tool_result answering it, as a single collapsible panel: tool
+// name and a status badge in the summary, pretty-printed arguments, and the
+// result run through the markdown pipeline (so code in tool output still
+// gets highlighted). Either call or res may be nil - a tool_result with no
+// matching call in this message renders on its own, badged "pending" if a
+// call exists but hasn't returned yet.
+func generateToolPanel(call *db.MessageSegment, res *db.MessageSegment) string {
+	name := "tool"
+	var argsJSON string
+	if call != nil {
+		if n, ok := call.Data["name"].(string); ok && n != "" {
+			name = n
+		}
+		if input, ok := call.Data["input"].(string); ok {
+			argsJSON = input
+		}
+	}
+
+	status := "pending"
+	panelClass := "message-part tool-panel"
+	if res != nil {
+		if isError, _ := res.Data["is_error"].(bool); isError {
+			status = "error"
+			panelClass += " tool-panel-error"
+		} else {
+			status = "ok"
+			panelClass += " tool-panel-ok"
+		}
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf(`
+            <details class="%s">
+                <summary><span class="tool-name">%s</span><span class="tool-status tool-status-%s">%s</span></summary>
+`, panelClass, html.EscapeString(name), status, strings.ToUpper(status)))
+
+	if argsJSON != "" {
+		result.WriteString(fmt.Sprintf("                <div class=\"tool-args-label\">Arguments</div>\n                <pre class=\"tool-args\">%s</pre>\n",
+			html.EscapeString(prettyJSONString(argsJSON))))
+	}
+
+	if call != nil {
+		if durationText := formatToolDuration(call.Data["duration"]); durationText != "" {
+			result.WriteString(fmt.Sprintf("                <div class=\"tool-duration\">%s</div>\n", html.EscapeString(durationText)))
+		}
+	}
+
+	if res != nil {
+		if errMsg, ok := res.Data["error"].(string); ok && errMsg != "" {
+			result.WriteString(fmt.Sprintf("                <div class=\"tool-error\">%s</div>\n", html.EscapeString(errMsg)))
+		}
+		result.WriteString(fmt.Sprintf("                <div class=\"tool-result-label\">Result</div>\n                <div class=\"tool-result-body\">%s</div>\n",
+			RenderMarkdownPart(res.Text)))
+	}
+
+	result.WriteString("            </details>\n")
+	return result.String()
+}
+
+// prettyJSONString re-indents a JSON string for display, falling back to the
+// original text when it isn't valid JSON (e.g. a plain-string argument).
+func prettyJSONString(raw string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return raw
+	}
+	pretty, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return raw
+	}
+	return string(pretty)
+}
+
+// formatToolDuration renders a tool call's duration (milliseconds, however
+// the provider encoded it) as a short human string, or "" when no duration
+// was recorded.
+func formatToolDuration(v interface{}) string {
+	var ms float64
+	switch d := v.(type) {
+	case float64:
+		ms = d
+	case string:
+		parsed, err := strconv.ParseFloat(d, 64)
+		if err != nil {
+			return ""
+		}
+		ms = parsed
+	default:
+		return ""
+	}
+	if ms <= 0 {
+		return ""
+	}
+	if ms < 1000 {
+		return fmt.Sprintf("%.0fms", ms)
+	}
+	return fmt.Sprintf("%.1fs", ms/1000)
+}
+
+// generateSessionNav renders the prev/next/index links in a per-session
+// page's header, or "" when nav is empty (the single-session export path).
+func generateSessionNav(nav NavLinks) string {
+	if nav.isEmpty() {
+		return ""
 	}
-	
-	return fmt.Sprintf(`
-    <div class="date-separator">
-        %s
-    </div>
-`, date)
-}
 
-// generateMessage creates a compact message layout
-func generateMessage(msg db.ParsedMessage, index int) string {
 	var result strings.Builder
+	result.WriteString("<div class=\"session-nav\">\n")
+	if nav.PrevHref != "" {
+		result.WriteString(fmt.Sprintf("<a class=\"session-nav-prev\" href=\"%s\">← %s</a>\n",
+			html.EscapeString(nav.PrevHref), html.EscapeString(nav.PrevLabel)))
+	}
+	if nav.IndexHref != "" {
+		result.WriteString(fmt.Sprintf("<a class=\"session-nav-index\" href=\"%s\">Index</a>\n", html.EscapeString(nav.IndexHref)))
+	}
+	if nav.NextHref != "" {
+		result.WriteString(fmt.Sprintf("<a class=\"session-nav-next\" href=\"%s\">%s →</a>\n",
+			html.EscapeString(nav.NextHref), html.EscapeString(nav.NextLabel)))
+	}
+	result.WriteString("</div>\n")
+	return result.String()
+}
 
-	// Message metadata - use time only format
-	timeOnly := "Unknown"
-	if msg.CreatedAt != nil {
-		timeOnly = formatTimeOnly(msg.CreatedAt)
+// generateBranchTree creates a collapsible list of every branch forked from
+// the session, or "" when there are none.
+func generateBranchTree(branches []db.Branch) string {
+	if len(branches) == 0 {
+		return ""
 	}
 
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("<details class=\"branch-tree\">\n<summary>Branches (%d)</summary>\n<ul>\n", len(branches)))
+	for _, br := range branches {
+		title := br.ID
+		if br.Title != nil && *br.Title != "" {
+			title = *br.Title
+		}
+		result.WriteString(fmt.Sprintf("<li>%s — forked from message %s</li>\n",
+			html.EscapeString(title), html.EscapeString(br.ParentMessageID)))
+	}
+	result.WriteString("</ul>\n</details>\n")
+	return result.String()
+}
+
+// generateDateSeparator creates a date separator line
+func generateDateSeparator(date string) string {
+	// Parse date to format it nicely
+	if t, err := time.Parse("2006-01-02", date); err == nil {
+		formattedDate := t.Format("Monday, January 2, 2006")
+		return fmt.Sprintf(`
+    <div class="date-separator">
+        %s
+    </div>
+`, formattedDate)
+	}
+	
+	return fmt.Sprintf(`
+    <div class="date-separator">
+        %s
+    </div>
+`, date)
+}
+
+// generateMessage creates a compact message layout
+func generateMessage(msg db.ParsedMessage, index int, opts RenderOptions) string {
+	var result strings.Builder
+
+	// Message metadata - render as a <time> element so the relative "N ago"
+	// label (see renderMessageTime) can be recomputed client-side.
+	timeHTML := renderMessageTime(msg.CreatedAt, opts)
+
 	var modelInfo []string
 	if msg.Model != nil && *msg.Model != "" {
 		modelInfo = append(modelInfo, *msg.Model)
@@ -495,13 +1074,13 @@ func generateMessage(msg db.ParsedMessage, index int) string {
 
 	// Generate message
 	result.WriteString(fmt.Sprintf(`
-    <div class="message" id="%s">
+    <div class="message" id="%s" data-role="%s">
         <div class="message-sidebar %s">
             <div class="role-badge" title="%s">%s</div>
             <div class="message-info">
                 <div class="message-time"><a href="#%s">%s</a></div>
-`, anchorName, html.EscapeString(msg.Role),
-		html.EscapeString(strings.Title(msg.Role)), getRoleEmoji(msg.Role), anchorName, html.EscapeString(timeOnly)))
+`, anchorName, html.EscapeString(strings.ToLower(msg.Role)), html.EscapeString(msg.Role),
+		html.EscapeString(strings.Title(msg.Role)), getRoleEmoji(msg.Role), anchorName, timeHTML))
 
 	// Add model info if available
 	if len(modelInfo) > 0 {
@@ -510,6 +1089,13 @@ func generateMessage(msg db.ParsedMessage, index int) string {
 `, html.EscapeString(strings.Join(modelInfo, "/"))))
 	}
 
+	// Add branch info if this message isn't on the trunk
+	if msg.BranchID != "" {
+		result.WriteString(fmt.Sprintf(`
+                <div class="message-model">branch: %s</div>
+`, html.EscapeString(msg.BranchID)))
+	}
+
 	// Close message info and sidebar
 	result.WriteString(`
             </div>
@@ -517,8 +1103,46 @@ func generateMessage(msg db.ParsedMessage, index int) string {
         <div class="message-content">
 `)
 
-	// Add message parts
-	for _, part := range msg.Parts {
+	// Add message parts. tool_call/tool_result segments are paired up front
+	// (matching a call's "id" to a result's "tool_call_id") so each tool
+	// invocation renders as one panel instead of two.
+	hasSegments := len(msg.Segments) == len(msg.Parts)
+	resultsByCallID := make(map[string]db.MessageSegment)
+	if hasSegments {
+		for _, segment := range msg.Segments {
+			if segment.Kind != "tool_result" {
+				continue
+			}
+			if callID, ok := segment.Data["tool_call_id"].(string); ok && callID != "" {
+				resultsByCallID[callID] = segment
+			}
+		}
+	}
+	consumedCallIDs := make(map[string]bool)
+
+	for i, part := range msg.Parts {
+		if hasSegments {
+			segment := msg.Segments[i]
+			if segment.Kind == "tool_call" {
+				callID, _ := segment.Data["id"].(string)
+				if res, ok := resultsByCallID[callID]; ok && callID != "" {
+					consumedCallIDs[callID] = true
+					result.WriteString(generateToolPanel(&segment, &res))
+				} else {
+					result.WriteString(generateToolPanel(&segment, nil))
+				}
+				continue
+			}
+			if segment.Kind == "tool_result" {
+				callID, _ := segment.Data["tool_call_id"].(string)
+				if callID != "" && consumedCallIDs[callID] {
+					continue // already rendered alongside its tool_call
+				}
+				result.WriteString(generateToolPanel(nil, &segment))
+				continue
+			}
+		}
+
 		// Check if this is a tool message (starts with emoji indicators)
 		isToolMessage := strings.HasPrefix(part, "üîß") || strings.HasPrefix(part, "üìã")
 		cssClass := "message-part"
@@ -528,7 +1152,7 @@ func generateMessage(msg db.ParsedMessage, index int) string {
 
 		result.WriteString(fmt.Sprintf(`
             <div class="%s">%s</div>
-`, cssClass, html.EscapeString(part)))
+`, cssClass, RenderMarkdownPart(part)))
 	}
 
 	result.WriteString(`
@@ -539,11 +1163,16 @@ func generateMessage(msg db.ParsedMessage, index int) string {
 	return result.String()
 }
 
-// generateHTMLFooter creates the HTML footer with JavaScript
-func generateHTMLFooter() string {
-	return `
+// generateHTMLFooter creates the HTML footer with JavaScript. When
+// opts.Relative is set, it also embeds a script that recomputes every
+// <time>'s "N ago" suffix (see renderMessageTime) from its datetime
+// attribute on load, so a page opened long after export still shows an
+// accurate relative label.
+func generateHTMLFooter(opts RenderOptions) string {
+	var result strings.Builder
+	result.WriteString(`
     </div>
-    
+
     <button class="back-to-top" onclick="scrollToTop()" title="Back to top">
         ‚Üë
     </button>
@@ -561,11 +1190,11 @@ func generateHTMLFooter() string {
                     e.preventDefault();
                     const target = document.querySelector(this.getAttribute('href'));
                     if (target) {
-                        target.scrollIntoView({ 
+                        target.scrollIntoView({
                             behavior: 'smooth',
                             block: 'center'
                         });
-                        
+
                         // Highlight the target message briefly
                         target.style.boxShadow = '0 0 20px rgba(102, 126, 234, 0.5)';
                         setTimeout(() => {
@@ -575,9 +1204,185 @@ func generateHTMLFooter() string {
                 });
             });
         });
+
+        // Search box, role filters, and the "N of M shown" counter. Indexes
+        // each message's rendered text once on load, then on every keystroke
+        // (debounced) hides non-matching .message elements and wraps matches
+        // in <mark>, restoring the original markup first so re-searching
+        // never compounds highlights from the previous term.
+        document.addEventListener('DOMContentLoaded', function () {
+            var searchBox = document.getElementById('search-box');
+            var counter = document.getElementById('match-counter');
+            var roleFilters = Array.prototype.slice.call(document.querySelectorAll('.role-filter'));
+            var entries = Array.prototype.slice.call(document.querySelectorAll('.message')).map(function (el) {
+                var content = el.querySelector('.message-content');
+                return {
+                    el: el,
+                    content: content,
+                    original: content ? content.innerHTML : '',
+                    text: (content ? content.textContent : '').toLowerCase()
+                };
+            });
+
+            if (!searchBox || !counter || entries.length === 0) {
+                return;
+            }
+
+            function activeRoles() {
+                var roles = {};
+                roleFilters.forEach(function (cb) {
+                    if (cb.checked) {
+                        roles[cb.value] = true;
+                    }
+                });
+                return roles;
+            }
+
+            function highlight(entry, term) {
+                if (!entry.content || !term) {
+                    return;
+                }
+                var escaped = term.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+                var re = new RegExp('(' + escaped + ')', 'ig');
+                var walker = document.createTreeWalker(entry.content, NodeFilter.SHOW_TEXT, null, false);
+                var textNodes = [];
+                var node;
+                while ((node = walker.nextNode())) {
+                    textNodes.push(node);
+                }
+                textNodes.forEach(function (textNode) {
+                    if (!re.test(textNode.nodeValue)) {
+                        return;
+                    }
+                    re.lastIndex = 0;
+                    var span = document.createElement('span');
+                    span.innerHTML = textNode.nodeValue.replace(re, '<mark>$1</mark>');
+                    textNode.parentNode.replaceChild(span, textNode);
+                });
+            }
+
+            function applyFilter() {
+                var term = searchBox.value.trim().toLowerCase();
+                var roles = activeRoles();
+                var shown = 0;
+
+                entries.forEach(function (entry) {
+                    if (entry.content) {
+                        entry.content.innerHTML = entry.original;
+                    }
+
+                    var roleOk = roles[entry.el.getAttribute('data-role')] === true;
+                    var textOk = !term || entry.text.indexOf(term) !== -1;
+                    var visible = roleOk && textOk;
+
+                    entry.el.style.display = visible ? '' : 'none';
+                    if (visible) {
+                        shown++;
+                        if (term) {
+                            highlight(entry, term);
+                        }
+                    }
+                });
+
+                counter.textContent = shown + ' of ' + entries.length + ' shown';
+
+                document.querySelectorAll('.toc-entry').forEach(function (link) {
+                    var tocRoleOk = roles[link.getAttribute('data-role')] === true;
+                    link.parentNode.style.display = tocRoleOk ? '' : 'none';
+                });
+            }
+
+            var debounceTimer = null;
+            searchBox.addEventListener('input', function () {
+                clearTimeout(debounceTimer);
+                debounceTimer = setTimeout(applyFilter, 200);
+            });
+            roleFilters.forEach(function (cb) {
+                cb.addEventListener('change', applyFilter);
+            });
+            document.addEventListener('keydown', function (e) {
+                if (e.key === '/' && document.activeElement !== searchBox) {
+                    e.preventDefault();
+                    searchBox.focus();
+                }
+            });
+
+            applyFilter();
+        });
+
+        // Collapse long tool-result bodies behind a "Show N more lines"
+        // toggle so a verbose tool call doesn't dominate the page.
+        document.addEventListener('DOMContentLoaded', function () {
+            var maxLines = 20;
+            document.querySelectorAll('.tool-result-body').forEach(function (body) {
+                var lines = body.innerText.split('\n').length;
+                if (lines <= maxLines) {
+                    return;
+                }
+
+                var remaining = lines - maxLines;
+                body.classList.add('truncated');
+
+                var toggle = document.createElement('button');
+                toggle.type = 'button';
+                toggle.className = 'tool-result-toggle';
+                toggle.textContent = 'Show ' + remaining + ' more lines';
+                toggle.addEventListener('click', function () {
+                    var isTruncated = body.classList.toggle('truncated');
+                    toggle.textContent = isTruncated ? 'Show ' + remaining + ' more lines' : 'Show less';
+                });
+
+                body.insertAdjacentElement('afterend', toggle);
+            });
+        });
+`)
+
+	if opts.Relative {
+		result.WriteString(fmt.Sprintf(`
+        // Recompute each <time>'s "N ago" suffix from its datetime
+        // attribute, so a page left open (or opened long after export)
+        // keeps showing an accurate relative label.
+        document.addEventListener('DOMContentLoaded', function () {
+            var locale = %s;
+            var rtf = (typeof Intl !== 'undefined' && Intl.RelativeTimeFormat)
+                ? new Intl.RelativeTimeFormat(locale || undefined, { numeric: 'auto' })
+                : null;
+
+            function relativeLabel(date) {
+                var divisions = [
+                    [60, 'second'], [60, 'minute'], [24, 'hour'],
+                    [7, 'day'], [4.34524, 'week'], [12, 'month'], [Infinity, 'year']
+                ];
+                var duration = (date.getTime() - Date.now()) / 1000;
+                for (var i = 0; i < divisions.length; i++) {
+                    if (Math.abs(duration) < divisions[i][0]) {
+                        var rounded = Math.round(duration);
+                        return rtf ? rtf.format(rounded, divisions[i][1]) : rounded + ' ' + divisions[i][1];
+                    }
+                    duration /= divisions[i][0];
+                }
+                return '';
+            }
+
+            document.querySelectorAll('time[datetime]').forEach(function (el) {
+                var date = new Date(el.getAttribute('datetime'));
+                if (isNaN(date.getTime())) {
+                    return;
+                }
+                var span = el.nextElementSibling;
+                if (span && span.classList.contains('relative-time')) {
+                    span.textContent = '· ' + relativeLabel(date);
+                }
+            });
+        });
+`, strconv.Quote(opts.Locale)))
+	}
+
+	result.WriteString(`
     </script>
 </body>
-</html>`
+</html>`)
+	return result.String()
 }
 
 // GenerateHTMLFilename generates a filename for the HTML export
@@ -590,11 +1395,385 @@ func GenerateHTMLFilename(session *db.Session) string {
 
 	// Generate timestamp prefix
 	prefix := time.Now().Format("2006-01-02_15-04")
-	if session.CreatedAt != nil {
-		if timestamp, err := time.Parse("1", *session.CreatedAt); err == nil {
-			prefix = timestamp.Format("2006-01-02_15-04")
-		}
+	if t := parseFlexibleTime(session.CreatedAt); t != nil {
+		prefix = t.Local().Format("2006-01-02_15-04")
 	}
 
 	return fmt.Sprintf("%s_%s.html", prefix, base)
 }
+
+// RenderIndexHTML renders a multi-session archive index: sessions are
+// sorted by CreatedAt and grouped by day (reusing generateDateSeparator's
+// styling), with one row per session linking to the HTML file
+// GenerateHTMLFilename would produce for it. Callers that want
+// model/provider summary, duration, and a first-user-message preview per
+// row should set session.Content to the JSON-encoded messages (the same
+// compatibility convention the export command already uses) before calling
+// this - sessions with no Content render title/count/timestamp only.
+func RenderIndexHTML(sessions []*db.Session) string {
+	sorted := make([]*db.Session, len(sessions))
+	copy(sorted, sessions)
+	sort.Slice(sorted, func(i, j int) bool {
+		ti, tj := parseFlexibleTime(sorted[i].CreatedAt), parseFlexibleTime(sorted[j].CreatedAt)
+		if ti == nil || tj == nil {
+			return sorted[i].ID < sorted[j].ID
+		}
+		return ti.Before(*tj)
+	})
+
+	var result strings.Builder
+	result.WriteString(generateIndexHeader())
+	result.WriteString("<div class=\"container\">\n<div class=\"header\">\n<h1>Session Archive</h1>\n</div>\n")
+	result.WriteString("<div class=\"conversation\">\n")
+
+	var lastDate string
+	for _, session := range sorted {
+		date := formatDateOnly(session.CreatedAt, time.Local)
+		if date != "" && date != lastDate {
+			result.WriteString(generateDateSeparator(date))
+			lastDate = date
+		}
+		result.WriteString(generateSessionRow(session))
+	}
+
+	result.WriteString("</div>\n</div>\n")
+	result.WriteString(generateIndexFooter())
+	return result.String()
+}
+
+// indexSessionMeta is the render-friendly summary of a session row, derived
+// from session.Metadata (provider/agent tags) and session.Content (a JSON
+// array of db.ParsedMessage, when the caller populated it).
+type indexSessionMeta struct {
+	Provider     string
+	MessageCount int
+	ModelSummary string
+	Duration     string
+	Preview      string
+}
+
+func parseIndexSessionMeta(session *db.Session) indexSessionMeta {
+	var meta indexSessionMeta
+
+	if session.MessageCount != nil {
+		meta.MessageCount = *session.MessageCount
+	}
+
+	if session.Metadata != nil && *session.Metadata != "" {
+		var m map[string]string
+		if err := json.Unmarshal([]byte(*session.Metadata), &m); err == nil {
+			meta.Provider = m["provider"]
+			if agent := m["agent"]; agent != "" && meta.Provider != "" {
+				meta.Provider = meta.Provider + "/" + agent
+			} else if agent != "" {
+				meta.Provider = agent
+			}
+		}
+	}
+
+	if session.Content == nil || *session.Content == "" {
+		return meta
+	}
+	var messages []db.ParsedMessage
+	if err := json.Unmarshal([]byte(*session.Content), &messages); err != nil {
+		return meta
+	}
+	if meta.MessageCount == 0 {
+		meta.MessageCount = len(messages)
+	}
+
+	models := make(map[string]bool)
+	var first, last *time.Time
+	for _, msg := range messages {
+		if msg.Model != nil && *msg.Model != "" {
+			models[*msg.Model] = true
+		}
+		if t := parseMessageTime(msg.CreatedAt); t != nil {
+			if first == nil || t.Before(*first) {
+				first = t
+			}
+			if last == nil || t.After(*last) {
+				last = t
+			}
+		}
+		if meta.Preview == "" && strings.EqualFold(msg.Role, "user") {
+			meta.Preview = tocPreview(msg)
+		}
+	}
+
+	if len(models) > 0 {
+		names := make([]string, 0, len(models))
+		for m := range models {
+			names = append(names, m)
+		}
+		sort.Strings(names)
+		meta.ModelSummary = strings.Join(names, ", ")
+	}
+	if first != nil && last != nil && last.After(*first) {
+		meta.Duration = formatDuration(last.Sub(*first))
+	}
+
+	return meta
+}
+
+// formatDuration renders a session's span as a short human string.
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%.1fh", d.Hours())
+	}
+}
+
+// generateSessionRow renders one session's row in the archive index.
+func generateSessionRow(session *db.Session) string {
+	title := "Session " + session.ID
+	if session.Title != nil && *session.Title != "" {
+		title = *session.Title
+	}
+	meta := parseIndexSessionMeta(session)
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("<a class=\"session-row\" href=\"%s\">\n", html.EscapeString(GenerateHTMLFilename(session))))
+	result.WriteString(fmt.Sprintf("<div class=\"session-row-title\">%s</div>\n", html.EscapeString(title)))
+
+	result.WriteString("<div class=\"session-row-meta\">\n")
+	result.WriteString(fmt.Sprintf("<span>%s</span>\n", html.EscapeString(FormatTimestamp(session.CreatedAt))))
+	result.WriteString(fmt.Sprintf("<span>%d msg</span>\n", meta.MessageCount))
+	if meta.ModelSummary != "" {
+		result.WriteString(fmt.Sprintf("<span>%s</span>\n", html.EscapeString(meta.ModelSummary)))
+	}
+	if meta.Provider != "" {
+		result.WriteString(fmt.Sprintf("<span>%s</span>\n", html.EscapeString(meta.Provider)))
+	}
+	if meta.Duration != "" {
+		result.WriteString(fmt.Sprintf("<span>%s</span>\n", html.EscapeString(meta.Duration)))
+	}
+	result.WriteString("</div>\n")
+
+	if meta.Preview != "" {
+		result.WriteString(fmt.Sprintf("<div class=\"session-row-preview\">%s</div>\n", html.EscapeString(meta.Preview)))
+	}
+
+	result.WriteString("</a>\n")
+	return result.String()
+}
+
+// generateIndexHeader is the archive index's counterpart of
+// generateHTMLHeader: the same toolbar/search styling, minus the
+// per-message role filters, which don't apply to a list of sessions.
+func generateIndexHeader() string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Session Archive</title>
+    <style>
+        * {
+            margin: 0;
+            padding: 0;
+            box-sizing: border-box;
+        }
+
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif;
+            line-height: 1.6;
+            color: #333;
+            background-color: #f5f5f5;
+        }
+
+        .container {
+            max-width: 100rem;
+            margin: 0 auto;
+            padding: 20px;
+        }
+
+        .header {
+            background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%);
+            color: white;
+            padding: 40px 20px;
+            text-align: center;
+            border-radius: 10px;
+            margin-bottom: 30px;
+            box-shadow: 0 4px 6px rgba(0,0,0,0.1);
+        }
+
+        .header h1 {
+            font-size: 2.5em;
+            font-weight: 300;
+        }
+
+        .toolbar {
+            position: sticky;
+            top: 0;
+            z-index: 900;
+            display: flex;
+            align-items: center;
+            gap: 15px;
+            background: white;
+            padding: 12px 20px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+        }
+
+        #search-box {
+            flex: 1;
+            padding: 8px 12px;
+            border: 1px solid #ddd;
+            border-radius: 6px;
+            font-size: 0.95em;
+        }
+
+        .match-counter {
+            font-size: 0.85em;
+            color: #666;
+            white-space: nowrap;
+        }
+
+        .conversation {
+            background: white;
+            border-radius: 10px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            overflow: hidden;
+        }
+
+        .date-separator {
+            background: #f8f9fa;
+            border-top: 1px solid #e9ecef;
+            border-bottom: 1px solid #e9ecef;
+            padding: 8px 20px;
+            text-align: center;
+            font-size: 0.85em;
+            font-weight: 600;
+            color: #6c757d;
+        }
+
+        .session-row {
+            display: block;
+            padding: 15px 20px;
+            border-bottom: 1px solid #f0f0f0;
+            color: inherit;
+            text-decoration: none;
+        }
+
+        .session-row:last-child {
+            border-bottom: none;
+        }
+
+        .session-row:hover {
+            background: #f8f9ff;
+        }
+
+        .session-row-title {
+            font-weight: 600;
+            margin-bottom: 4px;
+        }
+
+        .session-row-meta {
+            display: flex;
+            gap: 12px;
+            font-size: 0.8em;
+            color: #666;
+        }
+
+        .session-row-preview {
+            margin-top: 6px;
+            font-size: 0.85em;
+            color: #444;
+            overflow: hidden;
+            text-overflow: ellipsis;
+            white-space: nowrap;
+        }
+
+        mark {
+            background: #fff3a0;
+            color: inherit;
+            border-radius: 2px;
+        }
+    </style>
+</head>
+<body>
+    <div class="toolbar">
+        <input type="text" id="search-box" placeholder="Search sessions… (press / to focus)" aria-label="Search sessions">
+        <div class="match-counter" id="match-counter"></div>
+    </div>
+`)
+}
+
+// generateIndexFooter closes the archive index document with the same
+// debounced search/highlight/counter behavior as a per-session page, scoped
+// to .session-row elements instead of .message elements.
+func generateIndexFooter() string {
+	return `
+    <script>
+        document.addEventListener('DOMContentLoaded', function () {
+            var searchBox = document.getElementById('search-box');
+            var counter = document.getElementById('match-counter');
+            var rows = Array.prototype.slice.call(document.querySelectorAll('.session-row')).map(function (el) {
+                return { el: el, original: el.innerHTML, text: el.textContent.toLowerCase() };
+            });
+
+            if (!searchBox || !counter || rows.length === 0) {
+                return;
+            }
+
+            function highlight(row, term) {
+                var escaped = term.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+                var re = new RegExp('(' + escaped + ')', 'ig');
+                var walker = document.createTreeWalker(row.el, NodeFilter.SHOW_TEXT, null, false);
+                var textNodes = [];
+                var node;
+                while ((node = walker.nextNode())) {
+                    textNodes.push(node);
+                }
+                textNodes.forEach(function (textNode) {
+                    if (!re.test(textNode.nodeValue)) {
+                        return;
+                    }
+                    re.lastIndex = 0;
+                    var span = document.createElement('span');
+                    span.innerHTML = textNode.nodeValue.replace(re, '<mark>$1</mark>');
+                    textNode.parentNode.replaceChild(span, textNode);
+                });
+            }
+
+            function applyFilter() {
+                var term = searchBox.value.trim().toLowerCase();
+                var shown = 0;
+
+                rows.forEach(function (row) {
+                    row.el.innerHTML = row.original;
+                    var visible = !term || row.text.indexOf(term) !== -1;
+                    row.el.style.display = visible ? '' : 'none';
+                    if (visible) {
+                        shown++;
+                        if (term) {
+                            highlight(row, term);
+                        }
+                    }
+                });
+
+                counter.textContent = shown + ' of ' + rows.length + ' shown';
+            }
+
+            var debounceTimer = null;
+            searchBox.addEventListener('input', function () {
+                clearTimeout(debounceTimer);
+                debounceTimer = setTimeout(applyFilter, 200);
+            });
+            document.addEventListener('keydown', function (e) {
+                if (e.key === '/' && document.activeElement !== searchBox) {
+                    e.preventDefault();
+                    searchBox.focus();
+                }
+            });
+
+            applyFilter();
+        });
+    </script>
+</body>
+</html>`
+}
@@ -0,0 +1,56 @@
+package markdown
+
+import (
+	"testing"
+	"time"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestParseFlexibleTime(t *testing.T) {
+	tests := []struct {
+		name string
+		ts   *string
+		want *time.Time
+	}{
+		{
+			name: "nil timestamp",
+			ts:   nil,
+			want: nil,
+		},
+		{
+			name: "empty timestamp",
+			ts:   strPtr(""),
+			want: nil,
+		},
+		{
+			name: "unix seconds",
+			ts:   strPtr("1700000000"),
+			want: timePtr(time.Unix(1700000000, 0)),
+		},
+		{
+			name: "RFC3339",
+			ts:   strPtr("2023-11-14T22:13:20Z"),
+			want: timePtr(time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)),
+		},
+		{
+			name: "unparseable",
+			ts:   strPtr("not-a-timestamp"),
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseFlexibleTime(tt.ts)
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("parseFlexibleTime(%v) = %v, want %v", tt.ts, got, tt.want)
+			}
+			if got != nil && !got.Equal(*tt.want) {
+				t.Fatalf("parseFlexibleTime(%v) = %v, want %v", tt.ts, got, tt.want)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time { return &t }
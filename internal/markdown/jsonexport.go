@@ -0,0 +1,275 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"crush-session-explorer/internal/db"
+)
+
+// JSONSchemaVersion is the current version of the RenderJSON/RenderJSONL
+// export schema (see jsonExport). Bump it, and document the change here,
+// whenever a field is removed or its meaning changes incompatibly.
+const JSONSchemaVersion = 1
+
+// jsonExport is the root structure RenderJSON marshals, and the value
+// RenderJSONL splits across its session/message records.
+type jsonExport struct {
+	SchemaVersion int                 `json:"schema_version"`
+	Session       jsonSessionExport   `json:"session"`
+	Messages      []jsonMessageExport `json:"messages"`
+}
+
+// jsonSessionExport is the session half of jsonExport.
+type jsonSessionExport struct {
+	ID           string                 `json:"id"`
+	Title        string                 `json:"title,omitempty"`
+	CreatedAt    string                 `json:"created_at,omitempty"`
+	MessageCount int                    `json:"message_count,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// jsonMessageExport is one entry of jsonExport.Messages: db.ParsedMessage
+// normalized to an RFC3339 timestamp and typed parts, for downstream tools
+// (indexers, eval pipelines, diff viewers) that can't easily parse the
+// markdown/HTML renderers' pre-rendered strings.
+type jsonMessageExport struct {
+	ID        string           `json:"id"`
+	Role      string           `json:"role"`
+	CreatedAt string           `json:"created_at,omitempty"`
+	Model     string           `json:"model,omitempty"`
+	Provider  string           `json:"provider,omitempty"`
+	ParentID  string           `json:"parent_id,omitempty"`
+	BranchID  string           `json:"branch_id,omitempty"`
+	Parts     []jsonPartExport `json:"parts"`
+}
+
+// jsonPartExport is one typed entry of jsonMessageExport.Parts: "text",
+// "tool_call", "tool_result", or any other segment Kind carried through
+// unchanged (e.g. "code", "image" from AICS imports).
+type jsonPartExport struct {
+	Type       string          `json:"type"`
+	Text       string          `json:"text,omitempty"`
+	ToolName   string          `json:"tool_name,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+	Input      json.RawMessage `json:"input,omitempty"`
+	Output     json.RawMessage `json:"output,omitempty"`
+	IsError    bool            `json:"is_error,omitempty"`
+	Duration   string          `json:"duration,omitempty"`
+}
+
+// RenderJSON renders a session and messages as a single versioned JSON
+// document (see JSONSchemaVersion), for tools that want the full session in
+// one parse rather than streaming it line by line (see RenderJSONL).
+func RenderJSON(session *db.Session, messages []db.ParsedMessage) ([]byte, error) {
+	return json.MarshalIndent(buildJSONExport(session, messages), "", "  ")
+}
+
+// jsonlRecord is one line of RenderJSONL's output: a discriminated union so
+// a consumer can tell the session header apart from a message by "type"
+// alone, without relying on line position.
+type jsonlRecord struct {
+	Type    string             `json:"type"`
+	Session *jsonSessionExport `json:"session,omitempty"`
+	Message *jsonMessageExport `json:"message,omitempty"`
+}
+
+// RenderJSONL renders a session and messages as newline-delimited JSON: a
+// leading {"type":"session",...} record, then one {"type":"message",...}
+// record per message, so a jq-based consumer can stream large sessions
+// without holding the whole thing in memory.
+func RenderJSONL(session *db.Session, messages []db.ParsedMessage) ([]byte, error) {
+	export := buildJSONExport(session, messages)
+
+	var buf bytes.Buffer
+	sessionLine, err := json.Marshal(jsonlRecord{Type: "session", Session: &export.Session})
+	if err != nil {
+		return nil, err
+	}
+	buf.Write(sessionLine)
+	buf.WriteByte('\n')
+
+	for i := range export.Messages {
+		line, err := json.Marshal(jsonlRecord{Type: "message", Message: &export.Messages[i]})
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// buildJSONExport assembles the shared jsonExport value RenderJSON and
+// RenderJSONL both marshal.
+func buildJSONExport(session *db.Session, messages []db.ParsedMessage) jsonExport {
+	export := jsonExport{
+		SchemaVersion: JSONSchemaVersion,
+		Session: jsonSessionExport{
+			ID:        session.ID,
+			CreatedAt: toRFC3339(session.CreatedAt),
+		},
+	}
+
+	if session.Title != nil {
+		export.Session.Title = *session.Title
+	}
+	if session.MessageCount != nil {
+		export.Session.MessageCount = *session.MessageCount
+	}
+	if session.Metadata != nil && *session.Metadata != "" {
+		var metadata map[string]interface{}
+		if err := json.Unmarshal([]byte(*session.Metadata), &metadata); err == nil {
+			export.Session.Metadata = metadata
+		}
+	}
+
+	export.Messages = make([]jsonMessageExport, len(messages))
+	for i, msg := range messages {
+		entry := jsonMessageExport{
+			ID:        msg.ID,
+			Role:      msg.Role,
+			CreatedAt: toRFC3339(msg.CreatedAt),
+			BranchID:  msg.BranchID,
+			Parts:     partsToJSON(msg),
+		}
+		if msg.Model != nil {
+			entry.Model = *msg.Model
+		}
+		if msg.Provider != nil {
+			entry.Provider = *msg.Provider
+		}
+		if msg.ParentID != nil {
+			entry.ParentID = *msg.ParentID
+		}
+		export.Messages[i] = entry
+	}
+
+	return export
+}
+
+// partsToJSON splits one message's parts into typed jsonPartExport entries,
+// preferring msg.Segments (richest: carries tool duration/is_error), falling
+// back to msg.StructuredParts, and finally to a bare "text" part per
+// msg.Parts entry, since not every code path populates Segments.
+// generateMessage and RenderMarkdownWithBranches have no StructuredParts
+// tier: without Segments they render the plain part text.
+func partsToJSON(msg db.ParsedMessage) []jsonPartExport {
+	if len(msg.Segments) == len(msg.Parts) && len(msg.Segments) > 0 {
+		parts := make([]jsonPartExport, len(msg.Segments))
+		for i, segment := range msg.Segments {
+			parts[i] = segmentToJSONPart(segment)
+		}
+		return parts
+	}
+
+	if len(msg.StructuredParts) == len(msg.Parts) && len(msg.StructuredParts) > 0 {
+		parts := make([]jsonPartExport, len(msg.StructuredParts))
+		for i, sp := range msg.StructuredParts {
+			parts[i] = structuredPartToJSON(sp)
+		}
+		return parts
+	}
+
+	parts := make([]jsonPartExport, len(msg.Parts))
+	for i, text := range msg.Parts {
+		parts[i] = jsonPartExport{Type: "text", Text: text}
+	}
+	return parts
+}
+
+// segmentToJSONPart converts one db.MessageSegment to a jsonPartExport.
+func segmentToJSONPart(segment db.MessageSegment) jsonPartExport {
+	part := jsonPartExport{Type: segment.Kind, Text: segment.Text}
+	if part.Type == "" {
+		part.Type = "text"
+	}
+
+	switch segment.Kind {
+	case "tool_call":
+		if name, ok := segment.Data["name"].(string); ok {
+			part.ToolName = name
+		}
+		if id, ok := segment.Data["id"].(string); ok {
+			part.ToolCallID = id
+		}
+		if input, ok := segment.Data["input"].(string); ok {
+			part.Input = db.RawJSONOrString(input)
+		}
+		if duration, ok := segment.Data["duration"].(string); ok {
+			part.Duration = duration
+		}
+	case "tool_result":
+		if id, ok := segment.Data["tool_call_id"].(string); ok {
+			part.ToolCallID = id
+		}
+		if content, ok := segment.Data["content"].(string); ok {
+			part.Output = db.RawJSONOrString(content)
+		}
+		if isError, ok := segment.Data["is_error"].(bool); ok {
+			part.IsError = isError
+		}
+	}
+
+	return part
+}
+
+// structuredPartToJSON converts one db.Part (the StructuredParts fallback,
+// used when a message wasn't parsed with Segments populated) to a
+// jsonPartExport.
+func structuredPartToJSON(sp db.Part) jsonPartExport {
+	if sp.ToolCall != nil {
+		return jsonPartExport{
+			Type:       "tool_call",
+			Text:       sp.Text,
+			ToolName:   sp.ToolCall.Name,
+			ToolCallID: sp.ToolCall.ID,
+			Input:      db.RawJSONOrString(string(sp.ToolCall.Input)),
+		}
+	}
+	if sp.ToolResult != nil {
+		return jsonPartExport{
+			Type:       "tool_result",
+			Text:       sp.Text,
+			ToolCallID: sp.ToolResult.ToolCallID,
+			Output:     db.RawJSONOrString(string(sp.ToolResult.Output)),
+		}
+	}
+	return jsonPartExport{Type: "text", Text: sp.Text}
+}
+
+// toRFC3339 normalizes a timestamp string (Unix epoch or already-RFC3339)
+// to RFC3339, using the same fallback ladder as parseFlexibleTime; returns
+// "" when ts is nil/empty or parses as neither.
+func toRFC3339(ts *string) string {
+	t := parseFlexibleTime(ts)
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// GenerateJSONFilename generates a filename for a RenderJSON export, using
+// the same title/timestamp convention as GenerateFilename/GenerateHTMLFilename.
+func GenerateJSONFilename(session *db.Session) string {
+	base := slugify("session-" + session.ID[:8])
+	if session.Title != nil && *session.Title != "" {
+		base = slugify(*session.Title)
+	}
+
+	prefix := time.Now().Format("2006-01-02_15-04")
+	if t := parseFlexibleTime(session.CreatedAt); t != nil {
+		prefix = t.Local().Format("2006-01-02_15-04")
+	}
+
+	return fmt.Sprintf("%s_%s.json", prefix, base)
+}
+
+// GenerateJSONLFilename generates a filename for a RenderJSONL export.
+func GenerateJSONLFilename(session *db.Session) string {
+	return strings.TrimSuffix(GenerateJSONFilename(session), ".json") + ".jsonl"
+}
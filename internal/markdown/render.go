@@ -0,0 +1,157 @@
+package markdown
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// chromaFormatter renders highlighted code as classed <span>s rather than
+// inline styles, so the palette lives once in chromaCSS (appended to
+// generateHTMLHeader's <style> block) instead of being repeated per block.
+var chromaFormatter = chromahtml.New(
+	chromahtml.WithClasses(true),
+	chromahtml.ClassPrefix("chroma-"),
+	chromahtml.TabWidth(4),
+)
+
+var chromaStyle = styles.Get("github")
+
+// chromaCSS is computed once at package init and appended to every
+// per-session HTML document's <style> block (the archive index from
+// generateIndexHeader has no code blocks and omits it), so highlighted code renders
+// without a stylesheet round-trip.
+var chromaCSS = mustChromaCSS()
+
+func mustChromaCSS() string {
+	var buf bytes.Buffer
+	if err := chromaFormatter.WriteCSS(&buf, chromaStyle); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// codeHighlightRenderer overrides goldmark's default fenced/indented code
+// block rendering with chroma, the way gomuks' ui/messages/html package
+// keeps its codeblock renderer separate from the generic entity renderer
+// rather than special-casing code inline in the main walk.
+type codeHighlightRenderer struct{}
+
+func (codeHighlightRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, renderFencedCodeBlock)
+	reg.Register(ast.KindCodeBlock, renderCodeBlock)
+}
+
+func renderFencedCodeBlock(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	node := n.(*ast.FencedCodeBlock)
+	highlightNode(w, string(node.Language(source)), codeBlockSource(node, source))
+	return ast.WalkSkipChildren, nil
+}
+
+func renderCodeBlock(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	highlightNode(w, "", codeBlockSource(n.(*ast.CodeBlock), source))
+	return ast.WalkSkipChildren, nil
+}
+
+// linesNode is satisfied by both ast.FencedCodeBlock and ast.CodeBlock.
+type linesNode interface {
+	Lines() *text.Segments
+}
+
+func codeBlockSource(n linesNode, source []byte) string {
+	lines := n.Lines()
+	var buf bytes.Buffer
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		buf.Write(line.Value(source))
+	}
+	return buf.String()
+}
+
+// highlightNode tokenizes code with chroma (guessing a lexer from language,
+// then from content, then falling back to plain text) and writes the
+// resulting <pre><code> block straight to w. Any chroma failure falls back
+// to an unhighlighted, escaped block rather than dropping the code.
+func highlightNode(w util.BufWriter, language, code string) {
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		lexer = lexers.Analyse(code)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	iterator, err := lexer.Tokenise(nil, code)
+	if err != nil {
+		writeEscapedCodeBlock(w, language, code)
+		return
+	}
+
+	if err := chromaFormatter.Format(w, chromaStyle, iterator); err != nil {
+		writeEscapedCodeBlock(w, language, code)
+	}
+}
+
+func writeEscapedCodeBlock(w util.BufWriter, language, code string) {
+	w.WriteString(`<pre><code class="language-`)
+	w.WriteString(template.HTMLEscapeString(language))
+	w.WriteString(`">`)
+	w.WriteString(template.HTMLEscapeString(code))
+	w.WriteString("</code></pre>")
+}
+
+// markdownEngine is the shared goldmark instance behind RenderMarkdownPart:
+// GFM tables/strikethrough/autolinks, raw HTML left in place (the sanitizer
+// below, not goldmark, is the single place deciding what survives), and code
+// blocks routed through codeHighlightRenderer instead of goldmark's default.
+var markdownEngine = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(
+		goldmarkhtml.WithUnsafe(),
+		renderer.WithNodeRenderers(util.Prioritized(codeHighlightRenderer{}, 100)),
+	),
+)
+
+// partSanitizer is the allowlist every rendered part is run through:
+// bluemonday's UGC policy (prose, lists, tables, links, blockquotes, inline
+// code) plus the "class" attribute on the elements chroma/goldmark emit it
+// on, nothing else - no inline event handlers, no <script>/<iframe>.
+var partSanitizer = newPartSanitizer()
+
+func newPartSanitizer() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").OnElements("code", "span", "pre")
+	return p
+}
+
+// RenderMarkdownPart converts a single message part's markdown to sanitized,
+// syntax-highlighted HTML. It's the part-level counterpart of
+// RenderMarkdown(session, messages) string, used by generateMessage in place
+// of the old escape-and-dump-into-a-<div> rendering so tool output and
+// assistant prose share one renderer.
+func RenderMarkdownPart(part string) template.HTML {
+	var buf bytes.Buffer
+	if err := markdownEngine.Convert([]byte(part), &buf); err != nil {
+		return template.HTML(template.HTMLEscapeString(part))
+	}
+	return template.HTML(partSanitizer.SanitizeBytes(buf.Bytes()))
+}
@@ -3,6 +3,8 @@ package markdown
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -79,6 +81,14 @@ func slugify(text string) string {
 
 // RenderMarkdown converts a session and messages to markdown format
 func RenderMarkdown(session *db.Session, messages []db.ParsedMessage) string {
+	return RenderMarkdownWithBranches(session, messages, nil)
+}
+
+// RenderMarkdownWithBranches is the branch-aware variant of RenderMarkdown:
+// messages should already be restricted to whichever branch the caller wants
+// rendered (see db.ListMessagesOnBranch); branches, when non-empty, is
+// rendered as a collapsible tree listing every fork of the session.
+func RenderMarkdownWithBranches(session *db.Session, messages []db.ParsedMessage, branches []db.Branch) string {
 	var result strings.Builder
 
 	// Generate title
@@ -116,12 +126,17 @@ func RenderMarkdown(session *db.Session, messages []db.ParsedMessage) string {
 
 	result.WriteString("---\n\n")
 
+	result.WriteString(renderBranchTree(branches))
+
 	// Generate message content
 	for _, msg := range messages {
 		// Generate header
 		role := msg.Role
 		ts := FormatTimestamp(msg.CreatedAt)
 		header := fmt.Sprintf("## %s â€” %s", role, ts)
+		if msg.BranchID != "" {
+			header += fmt.Sprintf(" (branch: %s)", msg.BranchID)
+		}
 
 		// Add model/provider info if available
 		var modelInfo []string
@@ -139,7 +154,14 @@ func RenderMarkdown(session *db.Session, messages []db.ParsedMessage) string {
 
 		// Add message content
 		result.WriteString("<div>\n")
-		for _, part := range msg.Parts {
+		hasSegments := len(msg.Segments) == len(msg.Parts)
+		for i, part := range msg.Parts {
+			if hasSegments {
+				if segment := msg.Segments[i]; segment.Kind == "tool_call" || segment.Kind == "tool_result" {
+					result.WriteString(renderToolSegment(segment))
+					continue
+				}
+			}
 			result.WriteString(part + "\n")
 		}
 		result.WriteString("</div>\n\n")
@@ -148,6 +170,45 @@ func RenderMarkdown(session *db.Session, messages []db.ParsedMessage) string {
 	return result.String()
 }
 
+// renderToolSegment renders a tool_call/tool_result segment as a collapsible
+// block showing its structured input/output alongside the summary text.
+func renderToolSegment(segment db.MessageSegment) string {
+	var result strings.Builder
+	summary := "Tool call"
+	if segment.Kind == "tool_result" {
+		summary = "Tool result"
+	}
+	result.WriteString(fmt.Sprintf("<details>\n<summary>%s</summary>\n\n", summary))
+	result.WriteString(segment.Text + "\n")
+	if len(segment.Data) > 0 {
+		if encoded, err := json.MarshalIndent(segment.Data, "", "  "); err == nil {
+			result.WriteString(fmt.Sprintf("\n```json\n%s\n```\n", encoded))
+		}
+	}
+	result.WriteString("\n</details>\n\n")
+	return result.String()
+}
+
+// renderBranchTree renders a collapsible list of every branch forked from
+// the session, or "" when there are none.
+func renderBranchTree(branches []db.Branch) string {
+	if len(branches) == 0 {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("<details>\n<summary>Branches (%d)</summary>\n\n", len(branches)))
+	for _, br := range branches {
+		title := br.ID
+		if br.Title != nil && *br.Title != "" {
+			title = *br.Title
+		}
+		result.WriteString(fmt.Sprintf("- **%s** — forked from message `%s`\n", title, br.ParentMessageID))
+	}
+	result.WriteString("\n</details>\n\n")
+	return result.String()
+}
+
 // GenerateFilename generates a filename for the session
 func GenerateFilename(session *db.Session) string {
 	// Generate base name from title or session ID
@@ -166,3 +227,12 @@ func GenerateFilename(session *db.Session) string {
 
 	return fmt.Sprintf("%s_%s.md", prefix, base)
 }
+
+// WriteFile writes content to path, creating any missing parent directories
+// first.
+func WriteFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
@@ -0,0 +1,121 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// Agent groups a reusable persona on top of the Provider layer: a system
+// prompt, a curated toolbox, and a default model that can be attached when
+// starting a new session or replying to an existing one (e.g. "coding",
+// "review"). Agents are configured in YAML and loaded with LoadAgents.
+type Agent struct {
+	Name             string   `yaml:"name" json:"name"`
+	SystemPrompt     string   `yaml:"system_prompt" json:"system_prompt"`
+	Tools            []string `yaml:"tools,omitempty" json:"tools,omitempty"`
+	DefaultModel     string   `yaml:"default_model,omitempty" json:"default_model,omitempty"`
+	AllowedProviders []string `yaml:"allowed_providers,omitempty" json:"allowed_providers,omitempty"`
+}
+
+// AllowsProvider reports whether the agent may be attached to the named
+// provider. An empty AllowedProviders means no restriction.
+func (a Agent) AllowsProvider(name string) bool {
+	if len(a.AllowedProviders) == 0 {
+		return true
+	}
+	for _, p := range a.AllowedProviders {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AgentsConfig is the on-disk YAML shape for a set of configured agents.
+type AgentsConfig struct {
+	Agents []Agent `yaml:"agents"`
+}
+
+// LoadAgents reads and parses an agents.yaml file, returning the agents
+// keyed by name.
+func LoadAgents(path string) (map[string]Agent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agents config: %w", err)
+	}
+
+	var cfg AgentsConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse agents config %s: %w", path, err)
+	}
+
+	agents := make(map[string]Agent, len(cfg.Agents))
+	for _, a := range cfg.Agents {
+		agents[a.Name] = a
+	}
+	return agents, nil
+}
+
+// DefaultAgentsConfigPath returns the default location for agents.yaml,
+// alongside the client ID file managed by interchange.GetClientID.
+func DefaultAgentsConfigPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "crush-session-explorer", "agents.yaml")
+}
+
+// LoadDefaultAgents loads agents.yaml from DefaultAgentsConfigPath, returning
+// a nil map (not an error) if the file doesn't exist.
+func LoadDefaultAgents() (map[string]Agent, error) {
+	path := DefaultAgentsConfigPath()
+	if path == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return LoadAgents(path)
+}
+
+// sessionMetadata builds the JSON blob stored in db.Session.Metadata,
+// recording which provider (and, if any, agent persona) produced a session.
+// markdown.RenderMarkdown/RenderHTML already expect Metadata to hold a JSON
+// object, so this is the shape every provider should write into it.
+func sessionMetadata(providerName, agentName string) *string {
+	return sessionMetadataWithExtra(providerName, agentName, nil)
+}
+
+// sessionMetadataWithExtra is sessionMetadata plus provider-specific context
+// (e.g. CursorProvider's workspace path).
+func sessionMetadataWithExtra(providerName, agentName string, extra map[string]string) *string {
+	data := map[string]string{"provider": providerName}
+	if agentName != "" {
+		data["agent"] = agentName
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return &providerName
+	}
+	s := string(encoded)
+	return &s
+}
+
+// newSessionID generates a UUID v7 for a new session or message.
+func newSessionID() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.NewString()
+	}
+	return id.String()
+}
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"context"
 	"crush-session-explorer/internal/db"
 	"database/sql"
 	"encoding/json"
@@ -9,12 +10,15 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 )
 
 // ClaudeProvider implements the Provider interface for Claude Code/Desktop sessions
 type ClaudeProvider struct {
 	dbPath string
+
+	connMu sync.Mutex
 	conn   *sql.DB
 }
 
@@ -96,8 +100,12 @@ func (p *ClaudeProvider) Discover() (bool, error) {
 	return true, nil
 }
 
-// getConnection returns or creates a database connection
+// getConnection returns or creates a database connection. It's safe to call
+// concurrently (e.g. from the batch exporter's worker pool, where multiple
+// goroutines share one provider instance per provider name).
 func (p *ClaudeProvider) getConnection() (*sql.DB, error) {
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
 	if p.conn == nil {
 		conn, err := sql.Open("sqlite3", p.dbPath)
 		if err != nil {
@@ -167,12 +175,11 @@ func (p *ClaudeProvider) ListSessions(limit int) ([]db.Session, error) {
 			msgCount = 0
 		}
 
-		provider := "claude-code"
 		sessions = append(sessions, db.Session{
 			ID:           id,
 			Title:        &title,
 			CreatedAt:    &timestamp,
-			Metadata:     &provider,
+			Metadata:     sessionMetadata(p.Name(), ""),
 			MessageCount: &msgCount,
 		})
 	}
@@ -223,12 +230,11 @@ func (p *ClaudeProvider) FetchSession(sessionID string) (*db.Session, error) {
 		msgCount = 0
 	}
 
-	provider := "claude-code"
 	return &db.Session{
 		ID:           id,
 		Title:        &title,
 		CreatedAt:    &timestamp,
-		Metadata:     &provider,
+		Metadata:     sessionMetadata(p.Name(), ""),
 		MessageCount: &msgCount,
 	}, nil
 }
@@ -278,19 +284,330 @@ func (p *ClaudeProvider) ListMessages(sessionID string) ([]db.ParsedMessage, err
 		model := "claude"
 		provider := "anthropic"
 
+		parts, structuredParts, segments := parseClaudeContent(text)
+
+		messages = append(messages, db.ParsedMessage{
+			ID:              id,
+			Role:            role,
+			Parts:           parts,
+			StructuredParts: structuredParts,
+			Segments:        segments,
+			Model:           &model,
+			Provider:        &provider,
+			CreatedAt:       &timestamp,
+		})
+	}
+
+	return messages, nil
+}
+
+// ListSessionsCtx is the context-aware counterpart of ListSessions, so a
+// scan over a large Claude state.db can be bounded by a --timeout deadline
+// or cancelled on Ctrl-C.
+func (p *ClaudeProvider) ListSessionsCtx(ctx context.Context, limit int) ([]db.Session, error) {
+	conn, err := p.getConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT
+			uuid,
+			COALESCE(name, ''),
+			created_at,
+			updated_at
+		FROM conversations
+		ORDER BY updated_at DESC
+		LIMIT ?
+	`
+
+	rows, err := conn.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Claude conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []db.Session
+	for rows.Next() {
+		var id, name, createdAt, updatedAt string
+
+		err := rows.Scan(&id, &name, &createdAt, &updatedAt)
+		if err != nil {
+			continue
+		}
+
+		title := name
+		if title == "" {
+			title = "Untitled Conversation"
+		}
+
+		timestamp := updatedAt
+		if timestamp == "" {
+			timestamp = createdAt
+		}
+
+		var msgCount int
+		msgQuery := "SELECT COUNT(*) FROM chat_messages WHERE conversation_uuid = ?"
+		if err := conn.QueryRowContext(ctx, msgQuery, id).Scan(&msgCount); err != nil {
+			msgCount = 0
+		}
+
+		sessions = append(sessions, db.Session{
+			ID:           id,
+			Title:        &title,
+			CreatedAt:    &timestamp,
+			Metadata:     sessionMetadata(p.Name(), ""),
+			MessageCount: &msgCount,
+		})
+
+		if err := ctx.Err(); err != nil {
+			return sessions, err
+		}
+	}
+
+	return sessions, nil
+}
+
+// FetchSessionCtx is the context-aware counterpart of FetchSession.
+func (p *ClaudeProvider) FetchSessionCtx(ctx context.Context, sessionID string) (*db.Session, error) {
+	conn, err := p.getConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT
+			uuid,
+			COALESCE(name, ''),
+			created_at,
+			updated_at
+		FROM conversations
+		WHERE uuid = ?
+	`
+
+	var id, name, createdAt, updatedAt string
+	err = conn.QueryRowContext(ctx, query, sessionID).Scan(&id, &name, &createdAt, &updatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session not found: %s", sessionID)
+		}
+		return nil, fmt.Errorf("failed to fetch Claude session: %w", err)
+	}
+
+	title := name
+	if title == "" {
+		title = "Untitled Conversation"
+	}
+
+	timestamp := updatedAt
+	if timestamp == "" {
+		timestamp = createdAt
+	}
+
+	var msgCount int
+	msgQuery := "SELECT COUNT(*) FROM chat_messages WHERE conversation_uuid = ?"
+	if err := conn.QueryRowContext(ctx, msgQuery, id).Scan(&msgCount); err != nil {
+		msgCount = 0
+	}
+
+	return &db.Session{
+		ID:           id,
+		Title:        &title,
+		CreatedAt:    &timestamp,
+		Metadata:     sessionMetadata(p.Name(), ""),
+		MessageCount: &msgCount,
+	}, nil
+}
+
+// ListMessagesCtx is the context-aware counterpart of ListMessages.
+func (p *ClaudeProvider) ListMessagesCtx(ctx context.Context, sessionID string) ([]db.ParsedMessage, error) {
+	conn, err := p.getConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT
+			uuid,
+			COALESCE(sender, ''),
+			COALESCE(text, ''),
+			created_at
+		FROM chat_messages
+		WHERE conversation_uuid = ?
+		ORDER BY created_at ASC
+	`
+
+	rows, err := conn.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Claude messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []db.ParsedMessage
+	for rows.Next() {
+		var id, sender, text, createdAt string
+
+		err := rows.Scan(&id, &sender, &text, &createdAt)
+		if err != nil {
+			continue
+		}
+
+		role := "user"
+		if strings.ToLower(sender) == "assistant" || strings.ToLower(sender) == "claude" {
+			role = "assistant"
+		}
+
+		timestamp := p.normalizeTimestamp(createdAt)
+
+		model := "claude"
+		provider := "anthropic"
+
+		parts, structuredParts, segments := parseClaudeContent(text)
+
 		messages = append(messages, db.ParsedMessage{
-			ID:        id,
-			Role:      role,
-			Parts:     []string{text},
-			Model:     &model,
-			Provider:  &provider,
-			CreatedAt: &timestamp,
+			ID:              id,
+			Role:            role,
+			Parts:           parts,
+			StructuredParts: structuredParts,
+			Segments:        segments,
+			Model:           &model,
+			Provider:        &provider,
+			CreatedAt:       &timestamp,
 		})
+
+		if err := ctx.Err(); err != nil {
+			return messages, err
+		}
 	}
 
 	return messages, nil
 }
 
+// parseClaudeContent decodes a chat_messages row's stored text, which may be
+// either a plain string or a JSON array of Anthropic content blocks
+// (text/tool_use/tool_result), into the three parallel representations the
+// rest of the tool expects. Rows that aren't a JSON block array fall back to
+// a single text part.
+func parseClaudeContent(text string) ([]string, []db.Part, []db.MessageSegment) {
+	var blocks []map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &blocks); err != nil {
+		if strings.TrimSpace(text) == "" {
+			return nil, nil, nil
+		}
+		return []string{text}, []db.Part{{Text: text}}, []db.MessageSegment{{Kind: "text", Text: text}}
+	}
+
+	var parts []string
+	var structuredParts []db.Part
+	var segments []db.MessageSegment
+
+	for _, block := range blocks {
+		blockType, _ := block["type"].(string)
+		switch blockType {
+		case "tool_use":
+			name, _ := block["name"].(string)
+			id, _ := block["id"].(string)
+			inputRaw, err := json.Marshal(block["input"])
+			if err != nil {
+				inputRaw = nil
+			}
+			summary := fmt.Sprintf("🔧 Tool call: %s", name)
+			parts = append(parts, summary)
+			structuredParts = append(structuredParts, db.Part{
+				Text:     summary,
+				ToolCall: &db.ToolCallData{ID: id, Name: name, Input: inputRaw},
+			})
+			segments = append(segments, db.MessageSegment{
+				Kind: "tool_call",
+				Text: summary,
+				Data: map[string]interface{}{"name": name, "id": id, "input": block["input"]},
+			})
+
+		case "tool_result":
+			toolUseID, _ := block["tool_use_id"].(string)
+			output := flattenClaudeToolResultContent(block["content"])
+			outputRaw, err := json.Marshal(block["content"])
+			if err != nil {
+				outputRaw = nil
+			}
+			summary := fmt.Sprintf("📋 Tool result:\n%s", output)
+			parts = append(parts, summary)
+			structuredParts = append(structuredParts, db.Part{
+				Text:       summary,
+				ToolResult: &db.ToolResultData{ToolCallID: toolUseID, Output: outputRaw},
+			})
+			segments = append(segments, db.MessageSegment{
+				Kind: "tool_result",
+				Text: summary,
+				Data: map[string]interface{}{"tool_call_id": toolUseID, "content": output},
+			})
+
+		default: // "text" and any unrecognized block carrying plain text
+			blockText, _ := block["text"].(string)
+			if strings.TrimSpace(blockText) == "" {
+				continue
+			}
+			parts = append(parts, blockText)
+			structuredParts = append(structuredParts, db.Part{Text: blockText})
+			segments = append(segments, db.MessageSegment{Kind: "text", Text: blockText})
+		}
+	}
+
+	return parts, structuredParts, segments
+}
+
+// flattenClaudeToolResultContent renders an Anthropic tool_result's content
+// (a plain string, or a nested list of content blocks) down to display text.
+func flattenClaudeToolResultContent(content interface{}) string {
+	switch v := content.(type) {
+	case string:
+		return v
+	case []interface{}:
+		var pieces []string
+		for _, item := range v {
+			if block, ok := item.(map[string]interface{}); ok {
+				if text, ok := block["text"].(string); ok {
+					pieces = append(pieces, text)
+					continue
+				}
+			}
+			pieces = append(pieces, fmt.Sprintf("%v", item))
+		}
+		return strings.Join(pieces, "\n")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// NewSessionWithAgent is unsupported: Claude Desktop owns its own
+// conversations schema and this tool only reads it.
+func (p *ClaudeProvider) NewSessionWithAgent(agent Agent, initialPrompt string) (*db.Session, error) {
+	return nil, fmt.Errorf("provider %s does not support creating sessions", p.Name())
+}
+
+// ReplyWithAgent is unsupported; see NewSessionWithAgent.
+func (p *ClaudeProvider) ReplyWithAgent(sessionID string, agent Agent, message string) ([]db.ParsedMessage, error) {
+	return nil, fmt.Errorf("provider %s does not support replying to sessions", p.Name())
+}
+
+// CreateBranch is unsupported: Claude Desktop owns its own conversations
+// schema and this tool only reads it.
+func (p *ClaudeProvider) CreateBranch(sessionID, fromMessageID string) (string, error) {
+	return "", fmt.Errorf("provider %s does not support branching", p.Name())
+}
+
+// ListBranches always returns no branches: Claude Desktop conversations are
+// single linear threads.
+func (p *ClaudeProvider) ListBranches(sessionID string) ([]db.Branch, error) {
+	return nil, nil
+}
+
+// ListMessagesOnBranch synthesizes a single linear branch: branchID is
+// ignored and the conversation's full message list is always returned.
+func (p *ClaudeProvider) ListMessagesOnBranch(sessionID, branchID string) ([]db.ParsedMessage, error) {
+	return p.ListMessages(sessionID)
+}
+
 // normalizeTimestamp converts various timestamp formats to RFC3339
 func (p *ClaudeProvider) normalizeTimestamp(ts string) string {
 	if ts == "" {
@@ -335,7 +652,10 @@ func (p *ClaudeProvider) SetDBPath(path string) {
 		}
 	}
 	p.dbPath = path
+
 	// Close existing connection since path changed
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
 	if p.conn != nil {
 		p.conn.Close()
 		p.conn = nil
@@ -344,6 +664,8 @@ func (p *ClaudeProvider) SetDBPath(path string) {
 
 // Close closes the database connection
 func (p *ClaudeProvider) Close() error {
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
 	if p.conn != nil {
 		err := p.conn.Close()
 		p.conn = nil
@@ -374,3 +696,13 @@ func parseJSONContent(content string) []string {
 	// If not JSON, return as single part
 	return []string{content}
 }
+
+func init() {
+	Register("claude-code", func(cfg map[string]string) Provider {
+		if path := cfg["db"]; path != "" {
+			return NewClaudeProviderWithPath(path)
+		}
+		return NewClaudeProvider()
+	})
+	Alias("claude", "claude-code")
+}
@@ -0,0 +1,25 @@
+package providers
+
+import (
+	"context"
+	"time"
+)
+
+// WithDeadline returns a context derived from parent that is cancelled after
+// d elapses, along with a CancelFunc to release it early. It's built the same
+// way netstack's gonet adapter times out a deadline connection: an explicit
+// cancel channel closed by a time.AfterFunc timer, rather than relying on
+// context.WithTimeout's internal timer context. File-based providers (e.g.
+// CursorProvider, which fans out across many state.vscdb files rather than
+// handing a single query to a context-aware driver) can check ctx between
+// files, as CursorProvider.loadSessionsCtx does before each workspace, and
+// still honor the deadline.
+func WithDeadline(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	timer := time.AfterFunc(d, cancel)
+
+	return ctx, func() {
+		timer.Stop()
+		cancel()
+	}
+}
@@ -1,15 +1,21 @@
 package providers
 
 import (
+	"context"
 	"crush-session-explorer/internal/db"
 	"database/sql"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 )
 
 // CrushProvider implements the Provider interface for Crush sessions
 type CrushProvider struct {
 	dbPath string
+
+	connMu sync.Mutex
 	conn   *sql.DB
 }
 
@@ -49,8 +55,12 @@ func (p *CrushProvider) Discover() (bool, error) {
 	return true, nil
 }
 
-// getConnection returns or creates a database connection
+// getConnection returns or creates a database connection. It's safe to call
+// concurrently (e.g. from the batch exporter's worker pool, where multiple
+// goroutines share one provider instance per provider name).
 func (p *CrushProvider) getConnection() (*sql.DB, error) {
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
 	if p.conn == nil {
 		conn, err := db.Connect(p.dbPath)
 		if err != nil {
@@ -61,6 +71,17 @@ func (p *CrushProvider) getConnection() (*sql.DB, error) {
 	return p.conn, nil
 }
 
+// resolveMetadata restores a session's persisted metadata (e.g. an agent
+// binding set by NewSessionWithAgent), falling back to a fresh, agent-less
+// default when nothing was ever stored for it (sessions created before
+// session_metadata existed, or via a path that never set Session.Metadata).
+func (p *CrushProvider) resolveMetadata(conn *sql.DB, sessionID string) *string {
+	if stored, err := db.GetSessionMetadata(conn, sessionID); err == nil && stored != "" {
+		return &stored
+	}
+	return sessionMetadata(p.Name(), "")
+}
+
 // ListSessions retrieves sessions from Crush database
 func (p *CrushProvider) ListSessions(limit int) ([]db.Session, error) {
 	conn, err := p.getConnection()
@@ -73,10 +94,10 @@ func (p *CrushProvider) ListSessions(limit int) ([]db.Session, error) {
 		return nil, err
 	}
 
-	// Add provider metadata to each session
+	// Restore any persisted metadata (e.g. agent binding), falling back to
+	// a fresh, agent-less default for sessions that never had one stored.
 	for i := range sessions {
-		provider := "crush"
-		sessions[i].Metadata = &provider
+		sessions[i].Metadata = p.resolveMetadata(conn, sessions[i].ID)
 	}
 
 	return sessions, nil
@@ -94,9 +115,7 @@ func (p *CrushProvider) FetchSession(sessionID string) (*db.Session, error) {
 		return nil, err
 	}
 
-	// Add provider metadata
-	provider := "crush"
-	session.Metadata = &provider
+	session.Metadata = p.resolveMetadata(conn, session.ID)
 
 	return session, nil
 }
@@ -111,6 +130,156 @@ func (p *CrushProvider) ListMessages(sessionID string) ([]db.ParsedMessage, erro
 	return db.ListMessages(conn, sessionID)
 }
 
+// ListSessionsCtx is the context-aware counterpart of ListSessions.
+func (p *CrushProvider) ListSessionsCtx(ctx context.Context, limit int) ([]db.Session, error) {
+	conn, err := p.getConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := db.ListSessionsCtx(ctx, conn, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Metadata = p.resolveMetadata(conn, sessions[i].ID)
+	}
+
+	return sessions, nil
+}
+
+// FetchSessionCtx is the context-aware counterpart of FetchSession.
+func (p *CrushProvider) FetchSessionCtx(ctx context.Context, sessionID string) (*db.Session, error) {
+	conn, err := p.getConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := db.FetchSessionCtx(ctx, conn, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	session.Metadata = p.resolveMetadata(conn, session.ID)
+
+	return session, nil
+}
+
+// ListMessagesCtx is the context-aware counterpart of ListMessages.
+func (p *CrushProvider) ListMessagesCtx(ctx context.Context, sessionID string) ([]db.ParsedMessage, error) {
+	conn, err := p.getConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	return db.ListMessagesCtx(ctx, conn, sessionID)
+}
+
+// NewSessionWithAgent starts a new Crush session bound to agent, recording
+// initialPrompt as the first user message.
+func (p *CrushProvider) NewSessionWithAgent(agent Agent, initialPrompt string) (*db.Session, error) {
+	if !agent.AllowsProvider(p.Name()) {
+		return nil, fmt.Errorf("agent %q is not allowed on provider %q", agent.Name, p.Name())
+	}
+
+	conn, err := p.getConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	title := initialPrompt
+	if len(title) > 80 {
+		title = title[:80]
+	}
+
+	session := db.Session{
+		ID:        newSessionID(),
+		Title:     &title,
+		CreatedAt: &now,
+		Metadata:  sessionMetadata(p.Name(), agent.Name),
+	}
+	if err := db.InsertSession(conn, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	msg := db.ParsedMessage{
+		ID:        newSessionID(),
+		Role:      "user",
+		Parts:     []string{initialPrompt},
+		CreatedAt: &now,
+	}
+	if agent.DefaultModel != "" {
+		msg.Model = &agent.DefaultModel
+	}
+	if err := db.AppendMessage(conn, session.ID, msg); err != nil {
+		return nil, fmt.Errorf("failed to record initial prompt: %w", err)
+	}
+
+	count := 1
+	session.MessageCount = &count
+	return &session, nil
+}
+
+// ReplyWithAgent appends message to an existing Crush session as a new user
+// turn under agent, returning the session's full message list afterward.
+func (p *CrushProvider) ReplyWithAgent(sessionID string, agent Agent, message string) ([]db.ParsedMessage, error) {
+	if !agent.AllowsProvider(p.Name()) {
+		return nil, fmt.Errorf("agent %q is not allowed on provider %q", agent.Name, p.Name())
+	}
+
+	conn, err := p.getConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	msg := db.ParsedMessage{
+		ID:        newSessionID(),
+		Role:      "user",
+		Parts:     []string{message},
+		CreatedAt: &now,
+	}
+	if agent.DefaultModel != "" {
+		msg.Model = &agent.DefaultModel
+	}
+
+	if err := db.AppendMessage(conn, sessionID, msg); err != nil {
+		return nil, fmt.Errorf("failed to append message: %w", err)
+	}
+
+	return db.ListMessages(conn, sessionID)
+}
+
+// CreateBranch forks sessionID at fromMessageID in the Crush database,
+// returning the new branch's ID.
+func (p *CrushProvider) CreateBranch(sessionID, fromMessageID string) (string, error) {
+	conn, err := p.getConnection()
+	if err != nil {
+		return "", err
+	}
+	return db.CreateBranch(conn, sessionID, fromMessageID)
+}
+
+// ListBranches returns every branch forked from sessionID.
+func (p *CrushProvider) ListBranches(sessionID string) ([]db.Branch, error) {
+	conn, err := p.getConnection()
+	if err != nil {
+		return nil, err
+	}
+	return db.ListBranches(conn, sessionID)
+}
+
+// ListMessagesOnBranch returns sessionID's messages restricted to branchID.
+func (p *CrushProvider) ListMessagesOnBranch(sessionID, branchID string) ([]db.ParsedMessage, error) {
+	conn, err := p.getConnection()
+	if err != nil {
+		return nil, err
+	}
+	return db.ListMessagesOnBranch(conn, sessionID, branchID)
+}
+
 // SetDBPath allows setting a custom database path
 func (p *CrushProvider) SetDBPath(path string) {
 	// Expand home directory if needed
@@ -121,7 +290,10 @@ func (p *CrushProvider) SetDBPath(path string) {
 		}
 	}
 	p.dbPath = path
+
 	// Close existing connection since path changed
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
 	if p.conn != nil {
 		p.conn.Close()
 		p.conn = nil
@@ -130,6 +302,8 @@ func (p *CrushProvider) SetDBPath(path string) {
 
 // Close closes the database connection
 func (p *CrushProvider) Close() error {
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
 	if p.conn != nil {
 		err := p.conn.Close()
 		p.conn = nil
@@ -137,3 +311,12 @@ func (p *CrushProvider) Close() error {
 	}
 	return nil
 }
+
+func init() {
+	Register("crush", func(cfg map[string]string) Provider {
+		if path := cfg["db"]; path != "" {
+			return NewCrushProviderWithPath(path)
+		}
+		return NewCrushProvider()
+	})
+}
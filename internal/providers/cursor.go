@@ -0,0 +1,478 @@
+package providers
+
+import (
+	"context"
+	"crush-session-explorer/internal/db"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CursorProvider implements the Provider interface for Cursor's local
+// session storage. Unlike CrushProvider/ClaudeProvider, Cursor keeps one
+// SQLite state store per workspace (workspaceStorage/<hash>/state.vscdb, an
+// ItemTable key/value store), so this provider fans out across every
+// workspace it can find instead of opening a single database.
+type CursorProvider struct {
+	storageRoot string
+}
+
+// cursorChatKeys are the ItemTable keys Cursor stores chat/composer session
+// data under.
+var cursorChatKeys = []string{
+	"composer.composerData",
+	"workbench.panel.aichat.view.aichat.chatdata",
+}
+
+// NewCursorProvider creates a new Cursor provider instance, using the
+// platform-default workspaceStorage location.
+func NewCursorProvider() *CursorProvider {
+	return &CursorProvider{storageRoot: defaultCursorStorageRoot()}
+}
+
+// NewCursorProviderWithPath creates a new Cursor provider rooted at a custom
+// workspaceStorage directory.
+func NewCursorProviderWithPath(storageRoot string) *CursorProvider {
+	return &CursorProvider{storageRoot: storageRoot}
+}
+
+// Name returns the provider name
+func (p *CursorProvider) Name() string {
+	return "cursor"
+}
+
+// defaultCursorStorageRoot returns the default Cursor workspaceStorage
+// directory based on OS.
+func defaultCursorStorageRoot() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", "Cursor", "User", "workspaceStorage")
+	case "windows":
+		appData := os.Getenv("APPDATA")
+		if appData == "" {
+			appData = filepath.Join(home, "AppData", "Roaming")
+		}
+		return filepath.Join(appData, "Cursor", "User", "workspaceStorage")
+	case "linux":
+		return filepath.Join(home, ".config", "Cursor", "User", "workspaceStorage")
+	default:
+		return ""
+	}
+}
+
+// Discover checks whether any Cursor workspaceStorage state store exists.
+func (p *CursorProvider) Discover() (bool, error) {
+	paths, err := p.stateDBPaths()
+	if err != nil {
+		return false, nil
+	}
+	return len(paths) > 0, nil
+}
+
+// stateDBPaths enumerates every workspaceStorage/*/state.vscdb under storageRoot.
+func (p *CursorProvider) stateDBPaths() ([]string, error) {
+	if p.storageRoot == "" {
+		return nil, fmt.Errorf("cursor workspaceStorage path unknown for this platform")
+	}
+
+	entries, err := os.ReadDir(p.storageRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dbPath := filepath.Join(p.storageRoot, entry.Name(), "state.vscdb")
+		if _, err := os.Stat(dbPath); err == nil {
+			paths = append(paths, dbPath)
+		}
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// cursorWorkspacePath reads the workspace's root folder from the
+// workspace.json that sits next to state.vscdb, falling back to the
+// workspace hash directory name when that's missing.
+func cursorWorkspacePath(stateDBPath string) string {
+	workspaceDir := filepath.Dir(stateDBPath)
+	if data, err := os.ReadFile(filepath.Join(workspaceDir, "workspace.json")); err == nil {
+		var meta struct {
+			Folder string `json:"folder"`
+		}
+		if json.Unmarshal(data, &meta) == nil && meta.Folder != "" {
+			return strings.TrimPrefix(meta.Folder, "file://")
+		}
+	}
+	return filepath.Base(workspaceDir)
+}
+
+// cursorChatRow is the decoded shape of a composer/chat ItemTable value.
+type cursorChatRow struct {
+	ID           string              `json:"composerId"`
+	Name         string              `json:"name"`
+	CreatedAt    int64               `json:"createdAt"`
+	Conversation []cursorChatMessage `json:"conversation"`
+}
+
+// cursorChatMessage is a single turn within a Cursor composer/chat session.
+// Type follows Cursor's internal enum (1 = user, 2 = assistant); tool calls
+// carry a non-empty ToolName alongside raw JSON input/output.
+type cursorChatMessage struct {
+	ID         string          `json:"bubbleId"`
+	Type       int             `json:"type"`
+	Text       string          `json:"text"`
+	ToolCallID string          `json:"toolCallId,omitempty"`
+	ToolName   string          `json:"toolName,omitempty"`
+	ToolInput  json.RawMessage `json:"toolInput,omitempty"`
+	ToolOutput json.RawMessage `json:"toolResult,omitempty"`
+}
+
+// cursorSession pairs a decoded chat row with the workspace it came from.
+type cursorSession struct {
+	workspace string
+	row       cursorChatRow
+}
+
+// loadSessions reads every composer/chat row out of every workspace's
+// state.vscdb.
+func (p *CursorProvider) loadSessions() ([]cursorSession, error) {
+	paths, err := p.stateDBPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []cursorSession
+	for _, path := range paths {
+		conn, err := sql.Open("sqlite3", path)
+		if err != nil {
+			continue
+		}
+
+		placeholders := make([]string, len(cursorChatKeys))
+		args := make([]interface{}, len(cursorChatKeys))
+		for i, key := range cursorChatKeys {
+			placeholders[i] = "?"
+			args[i] = key
+		}
+		query := fmt.Sprintf("SELECT value FROM ItemTable WHERE key IN (%s)", strings.Join(placeholders, ", "))
+
+		rows, err := conn.Query(query, args...)
+		if err != nil {
+			conn.Close()
+			continue
+		}
+
+		workspace := cursorWorkspacePath(path)
+		for rows.Next() {
+			var value string
+			if err := rows.Scan(&value); err != nil {
+				continue
+			}
+			var row cursorChatRow
+			if err := json.Unmarshal([]byte(value), &row); err != nil || row.ID == "" {
+				continue
+			}
+			sessions = append(sessions, cursorSession{workspace: workspace, row: row})
+		}
+		rows.Close()
+		conn.Close()
+	}
+
+	return sessions, nil
+}
+
+// loadSessionsCtx is the context-aware counterpart of loadSessions. Cursor's
+// storage doesn't speak context natively (each workspace is its own
+// sql.Open, and most of the work is JSON normalization afterward), so this
+// checks ctx.Err() before opening each workspace and passes ctx to that
+// workspace's query - enough to bound a scan across many workspaceStorage
+// directories with providers.WithDeadline.
+func (p *CursorProvider) loadSessionsCtx(ctx context.Context) ([]cursorSession, error) {
+	paths, err := p.stateDBPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []cursorSession
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return sessions, err
+		}
+
+		conn, err := sql.Open("sqlite3", path)
+		if err != nil {
+			continue
+		}
+
+		placeholders := make([]string, len(cursorChatKeys))
+		args := make([]interface{}, len(cursorChatKeys))
+		for i, key := range cursorChatKeys {
+			placeholders[i] = "?"
+			args[i] = key
+		}
+		query := fmt.Sprintf("SELECT value FROM ItemTable WHERE key IN (%s)", strings.Join(placeholders, ", "))
+
+		rows, err := conn.QueryContext(ctx, query, args...)
+		if err != nil {
+			conn.Close()
+			continue
+		}
+
+		workspace := cursorWorkspacePath(path)
+		for rows.Next() {
+			var value string
+			if err := rows.Scan(&value); err != nil {
+				continue
+			}
+			var row cursorChatRow
+			if err := json.Unmarshal([]byte(value), &row); err != nil || row.ID == "" {
+				continue
+			}
+			sessions = append(sessions, cursorSession{workspace: workspace, row: row})
+		}
+		rows.Close()
+		conn.Close()
+	}
+
+	return sessions, nil
+}
+
+// toDBSession converts a decoded Cursor session to db.Session, carrying the
+// workspace path in Metadata (rendered alongside the session's other
+// metadata entries; no renderer groups sessions by it).
+func (s cursorSession) toDBSession() db.Session {
+	title := s.row.Name
+	if title == "" {
+		title = "Untitled Composer Session"
+	}
+
+	var createdAt *string
+	if s.row.CreatedAt > 0 {
+		ts := time.UnixMilli(s.row.CreatedAt).UTC().Format(time.RFC3339)
+		createdAt = &ts
+	}
+
+	count := len(s.row.Conversation)
+	return db.Session{
+		ID:           s.row.ID,
+		Title:        &title,
+		CreatedAt:    createdAt,
+		Metadata:     sessionMetadataWithExtra("cursor", "", map[string]string{"workspace": s.workspace}),
+		MessageCount: &count,
+	}
+}
+
+// toDBMessages converts a decoded Cursor session's conversation to
+// []db.ParsedMessage, mapping tool-call/tool-result turns into
+// StructuredParts so export.go's AICS conversion emits Content.Type ==
+// "tool_call" / "tool_result" for them, same as the other providers.
+func (s cursorSession) toDBMessages() []db.ParsedMessage {
+	messages := make([]db.ParsedMessage, 0, len(s.row.Conversation))
+	for i, turn := range s.row.Conversation {
+		role := "user"
+		if turn.Type == 2 {
+			role = "assistant"
+		}
+
+		var createdAt *string
+		if s.row.CreatedAt > 0 {
+			ts := time.UnixMilli(s.row.CreatedAt).UTC().Format(time.RFC3339)
+			createdAt = &ts
+		}
+
+		id := turn.ID
+		if id == "" {
+			id = fmt.Sprintf("%s-%d", s.row.ID, i)
+		}
+
+		msg := db.ParsedMessage{
+			ID:        id,
+			Role:      role,
+			CreatedAt: createdAt,
+		}
+
+		switch {
+		case turn.ToolName != "" && turn.ToolCallID != "" && len(turn.ToolOutput) == 0:
+			msg.Parts = []string{turn.Text}
+			msg.StructuredParts = []db.Part{{
+				Text:     turn.Text,
+				ToolCall: &db.ToolCallData{ID: turn.ToolCallID, Name: turn.ToolName, Input: turn.ToolInput},
+			}}
+		case turn.ToolCallID != "" && len(turn.ToolOutput) > 0:
+			msg.Parts = []string{turn.Text}
+			msg.StructuredParts = []db.Part{{
+				Text:       turn.Text,
+				ToolResult: &db.ToolResultData{ToolCallID: turn.ToolCallID, Output: turn.ToolOutput},
+			}}
+		default:
+			if strings.TrimSpace(turn.Text) == "" {
+				continue
+			}
+			msg.Parts = []string{turn.Text}
+			msg.StructuredParts = []db.Part{{Text: turn.Text}}
+		}
+
+		messages = append(messages, msg)
+	}
+
+	return messages
+}
+
+// ListSessions retrieves sessions across every Cursor workspace.
+func (p *CursorProvider) ListSessions(limit int) ([]db.Session, error) {
+	sessions, err := p.loadSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].row.CreatedAt > sessions[j].row.CreatedAt
+	})
+
+	if limit > 0 && len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+
+	result := make([]db.Session, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, s.toDBSession())
+	}
+	return result, nil
+}
+
+// FetchSession retrieves a specific session by ID, scanning every workspace.
+func (p *CursorProvider) FetchSession(sessionID string) (*db.Session, error) {
+	sessions, err := p.loadSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range sessions {
+		if s.row.ID == sessionID {
+			session := s.toDBSession()
+			return &session, nil
+		}
+	}
+
+	return nil, fmt.Errorf("session not found: %s", sessionID)
+}
+
+// ListMessages retrieves messages for a session, scanning every workspace.
+func (p *CursorProvider) ListMessages(sessionID string) ([]db.ParsedMessage, error) {
+	sessions, err := p.loadSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range sessions {
+		if s.row.ID == sessionID {
+			return s.toDBMessages(), nil
+		}
+	}
+
+	return nil, fmt.Errorf("session not found: %s", sessionID)
+}
+
+// ListSessionsCtx is the context-aware counterpart of ListSessions.
+func (p *CursorProvider) ListSessionsCtx(ctx context.Context, limit int) ([]db.Session, error) {
+	sessions, err := p.loadSessionsCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].row.CreatedAt > sessions[j].row.CreatedAt
+	})
+
+	if limit > 0 && len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+
+	result := make([]db.Session, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, s.toDBSession())
+	}
+	return result, nil
+}
+
+// FetchSessionCtx is the context-aware counterpart of FetchSession.
+func (p *CursorProvider) FetchSessionCtx(ctx context.Context, sessionID string) (*db.Session, error) {
+	sessions, err := p.loadSessionsCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range sessions {
+		if s.row.ID == sessionID {
+			session := s.toDBSession()
+			return &session, nil
+		}
+	}
+
+	return nil, fmt.Errorf("session not found: %s", sessionID)
+}
+
+// ListMessagesCtx is the context-aware counterpart of ListMessages.
+func (p *CursorProvider) ListMessagesCtx(ctx context.Context, sessionID string) ([]db.ParsedMessage, error) {
+	sessions, err := p.loadSessionsCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range sessions {
+		if s.row.ID == sessionID {
+			return s.toDBMessages(), nil
+		}
+	}
+
+	return nil, fmt.Errorf("session not found: %s", sessionID)
+}
+
+// NewSessionWithAgent is unsupported: Cursor owns its own composer/chat
+// schema and this tool only reads it.
+func (p *CursorProvider) NewSessionWithAgent(agent Agent, initialPrompt string) (*db.Session, error) {
+	return nil, fmt.Errorf("provider %s does not support creating sessions", p.Name())
+}
+
+// ReplyWithAgent is unsupported; see NewSessionWithAgent.
+func (p *CursorProvider) ReplyWithAgent(sessionID string, agent Agent, message string) ([]db.ParsedMessage, error) {
+	return nil, fmt.Errorf("provider %s does not support replying to sessions", p.Name())
+}
+
+// CreateBranch is unsupported; see NewSessionWithAgent.
+func (p *CursorProvider) CreateBranch(sessionID, fromMessageID string) (string, error) {
+	return "", fmt.Errorf("provider %s does not support branching", p.Name())
+}
+
+// ListBranches always returns no branches: Cursor composer sessions are
+// single linear threads.
+func (p *CursorProvider) ListBranches(sessionID string) ([]db.Branch, error) {
+	return nil, nil
+}
+
+// ListMessagesOnBranch synthesizes a single linear branch: branchID is
+// ignored and the session's full message list is always returned.
+func (p *CursorProvider) ListMessagesOnBranch(sessionID, branchID string) ([]db.ParsedMessage, error) {
+	return p.ListMessages(sessionID)
+}
+
+func init() {
+	Register("cursor", func(cfg map[string]string) Provider { return NewCursorProvider() })
+	Alias("cursor-ai", "cursor")
+}
@@ -1,6 +1,9 @@
 package providers
 
 import (
+	"context"
+	"sync"
+
 	"crush-session-explorer/internal/db"
 )
 
@@ -20,17 +23,131 @@ type Provider interface {
 
 	// ListMessages retrieves messages for a session
 	ListMessages(sessionID string) ([]db.ParsedMessage, error)
+
+	// ListSessionsCtx is the context-aware counterpart of ListSessions, so a
+	// long scan (e.g. a large Claude state.db) can be bounded by a --timeout
+	// deadline or cancelled on Ctrl-C.
+	ListSessionsCtx(ctx context.Context, limit int) ([]db.Session, error)
+
+	// FetchSessionCtx is the context-aware counterpart of FetchSession.
+	FetchSessionCtx(ctx context.Context, sessionID string) (*db.Session, error)
+
+	// ListMessagesCtx is the context-aware counterpart of ListMessages.
+	ListMessagesCtx(ctx context.Context, sessionID string) ([]db.ParsedMessage, error)
+
+	// NewSessionWithAgent starts a new session bound to agent, recording
+	// initialPrompt as the first user turn, and returns the created session.
+	NewSessionWithAgent(agent Agent, initialPrompt string) (*db.Session, error)
+
+	// ReplyWithAgent appends message to sessionID as a new turn under agent,
+	// returning the session's full message list afterward.
+	ReplyWithAgent(sessionID string, agent Agent, message string) ([]db.ParsedMessage, error)
+
+	// CreateBranch forks sessionID at fromMessageID, returning the new
+	// branch's ID.
+	CreateBranch(sessionID, fromMessageID string) (string, error)
+
+	// ListBranches returns every branch forked from sessionID.
+	ListBranches(sessionID string) ([]db.Branch, error)
+
+	// ListMessagesOnBranch returns sessionID's messages restricted to
+	// branchID (trunk messages up to the fork, plus everything on that
+	// branch). An empty branchID returns the trunk.
+	ListMessagesOnBranch(sessionID, branchID string) ([]db.ParsedMessage, error)
 }
 
-// DiscoverAllProviders finds all available providers on the system
-func DiscoverAllProviders() []Provider {
-	allProviders := []Provider{
-		NewCrushProvider(),
-		NewClaudeProvider(),
+// Registry is a pluggable set of provider factories, keyed by name, plus a
+// set of aliases that resolve to one of those names (e.g. "claude" ->
+// "claude-code"). A provider registers itself via Register in its own
+// file's init(), so adding a new one (Aider, a second SSH-backed provider,
+// ...) means adding a file rather than editing this one.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]func(cfg map[string]string) Provider
+	order     []string // canonical names, in registration order
+	aliases   map[string]string
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[string]func(cfg map[string]string) Provider),
+		aliases:   make(map[string]string),
+	}
+}
+
+// Register adds a named provider factory. cfg is passed through from
+// Get/GetProviderWithConfig verbatim, letting a provider read whatever
+// configuration it needs (e.g. SSHProvider's "target", CrushProvider's
+// "db").
+func (r *Registry) Register(name string, factory func(cfg map[string]string) Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.factories[name]; !exists {
+		r.order = append(r.order, name)
 	}
+	r.factories[name] = factory
+}
 
+// Alias registers alternate name as another way to look up canonical (e.g.
+// Alias("claude", "claude-code")). Aliases aren't included in
+// CanonicalNames, so DiscoverAllProviders doesn't probe the same provider
+// twice.
+func (r *Registry) Alias(alias, canonical string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.aliases[alias] = canonical
+}
+
+// Get resolves name (canonical or alias) to its factory and builds a
+// Provider from cfg, or nil if name isn't registered.
+func (r *Registry) Get(name string, cfg map[string]string) Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if canonical, ok := r.aliases[name]; ok {
+		name = canonical
+	}
+	factory := r.factories[name]
+	if factory == nil {
+		return nil
+	}
+	return factory(cfg)
+}
+
+// CanonicalNames returns every registered (non-alias) provider name, in
+// registration order.
+func (r *Registry) CanonicalNames() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, len(r.order))
+	copy(names, r.order)
+	return names
+}
+
+// defaultRegistry is the registry every provider's init() registers into,
+// and what Register/Alias/GetProvider/DiscoverAllProviders operate on.
+var defaultRegistry = NewRegistry()
+
+// Register adds name to the default registry (see Registry.Register).
+func Register(name string, factory func(cfg map[string]string) Provider) {
+	defaultRegistry.Register(name, factory)
+}
+
+// Alias adds alias to the default registry (see Registry.Alias).
+func Alias(alias, canonical string) {
+	defaultRegistry.Alias(alias, canonical)
+}
+
+// DiscoverAllProviders finds all available providers on the system: every
+// canonical name in the default registry is instantiated with no config
+// and probed with Discover.
+func DiscoverAllProviders() []Provider {
 	var available []Provider
-	for _, provider := range allProviders {
+	for _, name := range defaultRegistry.CanonicalNames() {
+		provider := defaultRegistry.Get(name, nil)
+		if provider == nil {
+			continue
+		}
 		if found, err := provider.Discover(); err == nil && found {
 			available = append(available, provider)
 		}
@@ -39,13 +156,17 @@ func DiscoverAllProviders() []Provider {
 	return available
 }
 
-// GetProvider returns a specific provider by name
+// GetProvider returns a specific provider by name (canonical or alias),
+// with no configuration. Providers that need configuration to do anything
+// useful (e.g. SSHProvider's --ssh target) should be built through
+// GetProviderWithConfig instead.
 func GetProvider(name string) Provider {
-	providers := map[string]Provider{
-		"crush":       NewCrushProvider(),
-		"claude-code": NewClaudeProvider(),
-		"claude":      NewClaudeProvider(),
-	}
+	return defaultRegistry.Get(name, nil)
+}
 
-	return providers[name]
+// GetProviderWithConfig returns a specific provider by name (canonical or
+// alias), built with cfg. See Registry.Register for what keys a given
+// provider reads out of cfg.
+func GetProviderWithConfig(name string, cfg map[string]string) Provider {
+	return defaultRegistry.Get(name, cfg)
 }
@@ -0,0 +1,174 @@
+package providers
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/pkg/sftp"
+)
+
+// SSHProvider wraps CrushProvider to read a Crush sqlite database that
+// lives on a remote host: Discover copies the remote file down to a local
+// temp file over SFTP once and points the embedded CrushProvider at that
+// local copy. Every other Provider method is the embedded CrushProvider's
+// and reads whatever database it points at, so it sees the remote data only
+// after Discover has run.
+type SSHProvider struct {
+	*CrushProvider
+	target    string // "user@host:path", as given to --ssh
+	localCopy string
+	fetched   bool
+}
+
+// NewSSHProvider creates an SSHProvider for target ("user@host:path"). The
+// remote file isn't read until Discover is called.
+func NewSSHProvider(target string) *SSHProvider {
+	return &SSHProvider{
+		CrushProvider: NewCrushProvider(),
+		target:        target,
+	}
+}
+
+// Name returns the provider name.
+func (p *SSHProvider) Name() string {
+	return "ssh"
+}
+
+// Discover copies the remote database down to a local temp file over SFTP
+// (once per SSHProvider instance), then delegates to CrushProvider.Discover
+// against that local copy.
+func (p *SSHProvider) Discover() (bool, error) {
+	if p.target == "" {
+		return false, fmt.Errorf("ssh provider requires --ssh user@host:path")
+	}
+	if err := p.ensureLocalCopy(); err != nil {
+		return false, err
+	}
+	return p.CrushProvider.Discover()
+}
+
+// ensureLocalCopy downloads the remote sqlite file once and points the
+// embedded CrushProvider at it; later calls are a no-op.
+func (p *SSHProvider) ensureLocalCopy() error {
+	if p.fetched {
+		return nil
+	}
+
+	userHost, remotePath, err := splitSSHTarget(p.target)
+	if err != nil {
+		return err
+	}
+
+	client, err := dialSSH(userHost)
+	if err != nil {
+		return fmt.Errorf("ssh dial %s: %w", userHost, err)
+	}
+	defer client.Close()
+
+	sftpClient, err := sftp.NewClient(client)
+	if err != nil {
+		return fmt.Errorf("sftp session to %s: %w", userHost, err)
+	}
+	defer sftpClient.Close()
+
+	remote, err := sftpClient.Open(remotePath)
+	if err != nil {
+		return fmt.Errorf("open remote %s: %w", remotePath, err)
+	}
+	defer remote.Close()
+
+	local, err := os.CreateTemp("", "crush-ssh-*.db")
+	if err != nil {
+		return fmt.Errorf("create local temp copy: %w", err)
+	}
+	defer local.Close()
+
+	if _, err := remote.WriteTo(local); err != nil {
+		return fmt.Errorf("copy remote database: %w", err)
+	}
+
+	p.localCopy = local.Name()
+	p.fetched = true
+	p.CrushProvider.SetDBPath(p.localCopy)
+	return nil
+}
+
+// Close removes the local temp copy in addition to closing the underlying
+// database connection.
+func (p *SSHProvider) Close() error {
+	err := p.CrushProvider.Close()
+	if p.localCopy != "" {
+		os.Remove(p.localCopy)
+	}
+	return err
+}
+
+// splitSSHTarget parses a --ssh value of the form "user@host:path" into its
+// "user@host" and "path" halves.
+func splitSSHTarget(target string) (userHost, remotePath string, err error) {
+	userHost, remotePath, ok := strings.Cut(target, ":")
+	if !ok || userHost == "" || remotePath == "" {
+		return "", "", fmt.Errorf("invalid --ssh target %q (expected user@host:path)", target)
+	}
+	return userHost, remotePath, nil
+}
+
+// dialSSH connects to userHost ("user@host", optionally "user@host:port")
+// using the local SSH agent for authentication and ~/.ssh/known_hosts for
+// host key verification, matching how a user would already be connecting to
+// that host with the system "ssh" command.
+func dialSSH(userHost string) (*ssh.Client, error) {
+	user, host, ok := strings.Cut(userHost, "@")
+	if !ok {
+		return nil, fmt.Errorf("invalid user@host %q", userHost)
+	}
+	if !strings.Contains(host, ":") {
+		host += ":22"
+	}
+
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, fmt.Errorf("SSH_AUTH_SOCK not set; start ssh-agent and add your key")
+	}
+	agentConn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, fmt.Errorf("connect to ssh-agent: %w", err)
+	}
+	agentClient := agent.NewClient(agentConn)
+
+	hostKeyCallback, err := knownHostsCallback()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeysCallback(agentClient.Signers)},
+		HostKeyCallback: hostKeyCallback,
+	}
+
+	return ssh.Dial("tcp", host, config)
+}
+
+// knownHostsCallback builds a HostKeyCallback from ~/.ssh/known_hosts, the
+// same trust store the system "ssh" command uses.
+func knownHostsCallback() (ssh.HostKeyCallback, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("resolve home directory: %w", err)
+	}
+	return knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+}
+
+func init() {
+	Register("ssh", func(cfg map[string]string) Provider {
+		return NewSSHProvider(cfg["target"])
+	})
+}